@@ -0,0 +1,290 @@
+package abitest
+
+// Generate C sources that check predicted locations against the compiler.
+//
+// The callee (abi_probe) is a small assembly routine that saves every argument
+// register and the top of the stack, so whatever signature the caller used, we
+// can look for each argument's (unique) value and report where it arrived.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Number of bytes of the stack the probe saves (from the return address)
+const stackBytes = 512
+
+// Locations we can observe, in the order the probe saves them
+var intRegisters = []string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
+
+const calleeSource = `/* Generated by gosmeagle gen-abi-tests - do not edit */
+#if !defined(__x86_64__)
+#error "gosmeagle ABI tests currently only support x86_64"
+#endif
+
+unsigned long abi_int_regs[6];
+unsigned char abi_sse_regs[8][16];
+unsigned char abi_stack[%d];
+
+/* Save argument registers and the stack, whatever the signature is */
+__asm__(
+    ".text\n"
+    ".globl abi_probe\n"
+    ".type abi_probe, @function\n"
+    "abi_probe:\n"
+    "    movq %%rdi, abi_int_regs+0(%%rip)\n"
+    "    movq %%rsi, abi_int_regs+8(%%rip)\n"
+    "    movq %%rdx, abi_int_regs+16(%%rip)\n"
+    "    movq %%rcx, abi_int_regs+24(%%rip)\n"
+    "    movq %%r8, abi_int_regs+32(%%rip)\n"
+    "    movq %%r9, abi_int_regs+40(%%rip)\n"
+    "    movdqu %%xmm0, abi_sse_regs+0(%%rip)\n"
+    "    movdqu %%xmm1, abi_sse_regs+16(%%rip)\n"
+    "    movdqu %%xmm2, abi_sse_regs+32(%%rip)\n"
+    "    movdqu %%xmm3, abi_sse_regs+48(%%rip)\n"
+    "    movdqu %%xmm4, abi_sse_regs+64(%%rip)\n"
+    "    movdqu %%xmm5, abi_sse_regs+80(%%rip)\n"
+    "    movdqu %%xmm6, abi_sse_regs+96(%%rip)\n"
+    "    movdqu %%xmm7, abi_sse_regs+112(%%rip)\n"
+    "    movq %%rsp, %%rsi\n"
+    "    leaq abi_stack(%%rip), %%rdi\n"
+    "    movl $%d, %%ecx\n"
+    "    rep movsq\n"
+    "    ret\n"
+    ".size abi_probe, .-abi_probe\n"
+);
+`
+
+const callerHeader = `/* Generated by gosmeagle gen-abi-tests - do not edit */
+#include <stdio.h>
+#include <string.h>
+#include <stdint.h>
+#include <stddef.h>
+#include <stdbool.h>
+
+extern unsigned long abi_int_regs[6];
+extern unsigned char abi_sse_regs[8][16];
+extern unsigned char abi_stack[%d];
+extern void abi_probe(void);
+
+static const char *int_names[] = {"%s"};
+
+/* Registers and stack slots already matched to an argument of this test */
+static unsigned abi_claimed_int, abi_claimed_sse;
+static unsigned long long abi_claimed_stack;
+
+static void abi_reset(void) {
+    abi_claimed_int = abi_claimed_sse = 0;
+    abi_claimed_stack = 0;
+}
+
+/* Find where the bytes of an argument arrived, and print it as a gosmeagle location.
+   A bool is only 0 or 1, so the rest of its (zero extended) register has to be 0. */
+static void abi_find(const char *function, const char *name, const void *value, size_t size, int is_float, int is_bool) {
+    char where[64] = "unknown";
+    size_t i, slot;
+    if (is_float) {
+        for (i = 0; i < 8; i++) {
+            if (!(abi_claimed_sse & (1u << i)) && size <= 16 && memcmp(abi_sse_regs[i], value, size) == 0) {
+                abi_claimed_sse |= 1u << i;
+                snprintf(where, sizeof(where), "%%%%xmm%%zu", i);
+                goto found;
+            }
+        }
+    } else if (size <= 8) {
+        for (i = 0; i < 6; i++) {
+            if (abi_claimed_int & (1u << i)) {
+                continue;
+            }
+            if (is_bool && (abi_int_regs[i] & 0xffffff00UL) != 0) {
+                continue;
+            }
+            if (memcmp(&abi_int_regs[i], value, size) == 0) {
+                abi_claimed_int |= 1u << i;
+                snprintf(where, sizeof(where), "%%s", int_names[i]);
+                goto found;
+            }
+        }
+    } else {
+        /* Larger integers are split over a pair of registers */
+        for (i = 0; i + 1 < 6; i++) {
+            if (abi_claimed_int & (3u << i)) {
+                continue;
+            }
+            if (memcmp(&abi_int_regs[i], value, 8) == 0 &&
+                memcmp(&abi_int_regs[i + 1], (const char *)value + 8, size - 8) == 0) {
+                abi_claimed_int |= 3u << i;
+                snprintf(where, sizeof(where), "%%s | %%s", int_names[i], int_names[i + 1]);
+                goto found;
+            }
+        }
+    }
+
+    /* The stack starts at the return address, so the first slot is framebase+8 */
+    for (i = 8; i + size <= sizeof(abi_stack); i += 8) {
+        if (abi_claimed_stack & (1ULL << (i / 8))) {
+            continue;
+        }
+        if (memcmp(abi_stack + i, value, size) == 0) {
+            for (slot = i / 8; slot < (i + size + 7) / 8; slot++) {
+                abi_claimed_stack |= 1ULL << slot;
+            }
+            snprintf(where, sizeof(where), "framebase+%%zu", i);
+            goto found;
+        }
+    }
+found:
+    printf("%%s %%s %%s\n", function, name, where);
+}
+
+`
+
+const makefileSource = `# Generated by gosmeagle gen-abi-tests
+# Build with the local compiler, and compare observed to predicted locations
+CC ?= cc
+CFLAGS ?= -O0
+
+all: check
+
+abi_test: callee.c caller.c
+	$(CC) $(CFLAGS) -o abi_test callee.c caller.c
+
+observed.txt: abi_test
+	./abi_test > observed.txt
+
+check: observed.txt
+	diff -u expected.txt observed.txt && echo "All locations match"
+
+clean:
+	rm -f abi_test observed.txt
+`
+
+// sentinel returns a C expression with a value unique to argument i. The low
+// byte of each integer (and pointer) is unique too, so a value that is only
+// partly compared can't match another argument. A bool can only be 0 or 1.
+func sentinel(arg Argument, i int) string {
+	low := lowByte(i)
+	switch arg.Class {
+	case "Pointer":
+		return fmt.Sprintf("(void *)(uintptr_t)0x7e57%08x%02xULL", i+1, low)
+	case "Float":
+		return fmt.Sprintf("(%s)%d.%d", arg.CType, 1000+i, 25+i)
+	case "Bool":
+		return fmt.Sprintf("(%s)%d", arg.CType, i&1)
+	}
+	switch arg.Size {
+	case 1:
+		return fmt.Sprintf("(%s)0x%02x", arg.CType, low)
+	case 2:
+		return fmt.Sprintf("(%s)0x5a%02x", arg.CType, low)
+	case 4:
+		return fmt.Sprintf("(%s)0x5a%04x%02x", arg.CType, i+1, low)
+	case 16:
+		return fmt.Sprintf("((%s)0x%016xULL << 64 | (%s)0x5a5a5a5a%06x%02xULL)", arg.CType, 0x1111222233330000+uint64(i),
+			arg.CType, i+1, low)
+	}
+	return fmt.Sprintf("(%s)0x5a5a5a5a%06x%02xULL", arg.CType, i+1, low)
+}
+
+// lowByte is the low byte of the sentinel of argument i: never 0 or 1 (the
+// values of a bool), and below 0x80 so it fits in a (signed) char
+func lowByte(i int) int {
+	return 0x10 + i%0x70
+}
+
+// compareSize is how many bytes of a value are significant (x87 long double is 10)
+func compareSize(arg Argument) string {
+	if arg.Class == "Float" && arg.Size == 16 {
+		return "10"
+	}
+	return fmt.Sprintf("sizeof(%s)", arg.CType)
+}
+
+// identifier makes a function name safe to use in a C identifier
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// callerTest writes the test function for one signature
+func callerTest(sig Signature, index int) string {
+	var b strings.Builder
+	types := []string{}
+	names := []string{}
+	for _, arg := range sig.Arguments {
+		types = append(types, arg.CType)
+	}
+	if len(types) == 0 {
+		types = append(types, "void")
+	}
+	returnType := sig.Return
+	if returnType == "" {
+		returnType = "void"
+	}
+
+	fmt.Fprintf(&b, "/* %s */\n", sig.Name)
+	fmt.Fprintf(&b, "typedef %s (*abi_fn_%d_t)(%s);\n\n", returnType, index, strings.Join(types, ", "))
+	fmt.Fprintf(&b, "static void abi_test_%d_%s(void) {\n", index, identifier(sig.Name))
+	for i, arg := range sig.Arguments {
+		name := fmt.Sprintf("v%d", i)
+		names = append(names, name)
+		fmt.Fprintf(&b, "    %s %s = %s;\n", arg.CType, name, sentinel(arg, i))
+	}
+	fmt.Fprintf(&b, "    ((abi_fn_%d_t)(void *)abi_probe)(%s);\n", index, strings.Join(names, ", "))
+	b.WriteString("    abi_reset();\n")
+	for i, arg := range sig.Arguments {
+		isFloat, isBool := 0, 0
+		if arg.Class == "Float" && arg.Size <= 8 {
+			isFloat = 1
+		}
+		if arg.Class == "Bool" {
+			isBool = 1
+		}
+		fmt.Fprintf(&b, "    abi_find(\"%s\", \"%s\", &%s, %s, %d, %d);\n", sig.Name, arg.Name, names[i], compareSize(arg),
+			isFloat, isBool)
+	}
+	b.WriteString("}\n\n")
+	return b.String()
+}
+
+// Write generates callee.c, caller.c, expected.txt and a Makefile in outdir
+func Write(outdir string, sigs []Signature) error {
+	err := os.MkdirAll(outdir, 0755)
+	if err != nil {
+		return err
+	}
+
+	callee := fmt.Sprintf(calleeSource, stackBytes, stackBytes/8)
+	if err := os.WriteFile(filepath.Join(outdir, "callee.c"), []byte(callee), 0644); err != nil {
+		return err
+	}
+
+	var caller strings.Builder
+	var expected strings.Builder
+	caller.WriteString(fmt.Sprintf(callerHeader, stackBytes, strings.Join(intRegisters, "\", \"")))
+	for i, sig := range sigs {
+		caller.WriteString(callerTest(sig, i))
+		for _, arg := range sig.Arguments {
+			fmt.Fprintf(&expected, "%s %s %s\n", sig.Name, arg.Name, arg.Location)
+		}
+	}
+	caller.WriteString("int main(void) {\n")
+	for i, sig := range sigs {
+		fmt.Fprintf(&caller, "    abi_test_%d_%s();\n", i, identifier(sig.Name))
+	}
+	caller.WriteString("    return 0;\n}\n")
+
+	if err := os.WriteFile(filepath.Join(outdir, "caller.c"), []byte(caller.String()), 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(outdir, "expected.txt"), []byte(expected.String()), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outdir, "Makefile"), []byte(makefileSource), 0644)
+}
@@ -0,0 +1,236 @@
+package abitest
+
+// Signatures are the functions we generate ABI conformance tests for. They
+// come from a corpus (with locations already predicted) or from a list of
+// C declarations (and we predict the locations with the x86_64 allocator).
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/corpus"
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/parsers/x86_64"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// An Argument is one parameter with a C type and a predicted location
+type Argument struct {
+	Name     string
+	CType    string // the C spelling of the type
+	Class    string // Int, Uint, Char, Uchar, Bool, Float, Pointer
+	Size     int64
+	Location string // where gosmeagle predicts it arrives
+}
+
+// A Signature is a function to test
+type Signature struct {
+	Name      string
+	Return    string
+	Arguments []Argument
+}
+
+// A cType describes how a C type spelling is classified on x86_64 (LP64)
+type cType struct {
+	Class string
+	Size  int64
+}
+
+var cTypes = map[string]cType{
+	"char": {"Char", 1}, "signed char": {"Char", 1}, "unsigned char": {"Uchar", 1},
+	"short": {"Int", 2}, "short int": {"Int", 2}, "unsigned short": {"Uint", 2}, "short unsigned int": {"Uint", 2},
+	"int": {"Int", 4}, "signed": {"Int", 4}, "unsigned": {"Uint", 4}, "unsigned int": {"Uint", 4},
+	"long": {"Int", 8}, "long int": {"Int", 8}, "unsigned long": {"Uint", 8}, "long unsigned int": {"Uint", 8},
+	"long long": {"Int", 8}, "long long int": {"Int", 8}, "unsigned long long": {"Uint", 8},
+	"long long unsigned int": {"Uint", 8}, "__int128": {"Int", 16}, "unsigned __int128": {"Uint", 16},
+	"__int128 unsigned": {"Uint", 16}, "_Bool": {"Bool", 1}, "bool": {"Bool", 1},
+	"float": {"Float", 4}, "double": {"Float", 8}, "long double": {"Float", 16},
+	"size_t": {"Uint", 8}, "ssize_t": {"Int", 8}, "intptr_t": {"Int", 8}, "uintptr_t": {"Uint", 8},
+	"int8_t": {"Char", 1}, "uint8_t": {"Uchar", 1}, "int16_t": {"Int", 2}, "uint16_t": {"Uint", 2},
+	"int32_t": {"Int", 4}, "uint32_t": {"Uint", 4}, "int64_t": {"Int", 8}, "uint64_t": {"Uint", 8},
+}
+
+// normalizeType drops qualifiers and extra whitespace from a C type
+func normalizeType(ctype string) string {
+	fields := []string{}
+	for _, field := range strings.Fields(ctype) {
+		switch field {
+		case "const", "volatile", "restrict", "extern", "static":
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return strings.Join(fields, " ")
+}
+
+// lookupType returns the class and size of a C type spelling
+func lookupType(ctype string) (cType, bool) {
+	ctype = normalizeType(ctype)
+	if strings.Contains(ctype, "*") {
+		return cType{"Pointer", 8}, true
+	}
+	t, ok := cTypes[ctype]
+	return t, ok
+}
+
+// ParseSignatures reads C declarations, one per line (e.g., long add(long a, double b);)
+func ParseSignatures(r io.Reader) ([]Signature, error) {
+	sigs := []Signature{}
+	scanner := bufio.NewScanner(r)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		sig, err := parseSignature(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineno, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, scanner.Err()
+}
+
+// parseSignature parses a single C declaration and predicts argument locations
+func parseSignature(line string) (Signature, error) {
+	line = strings.TrimSuffix(strings.TrimSpace(line), ";")
+	open := strings.Index(line, "(")
+	close := strings.LastIndex(line, ")")
+	if open < 0 || close < open {
+		return Signature{}, fmt.Errorf("cannot parse declaration %q", line)
+	}
+
+	// The name is the last identifier before the parenthesis
+	returnType, name := splitDeclarator(line[:open])
+	if name == "" {
+		return Signature{}, fmt.Errorf("missing function name in %q", line)
+	}
+	sig := Signature{Name: name, Return: returnType}
+
+	params := strings.TrimSpace(line[open+1 : close])
+	if params == "" || params == "void" {
+		return sig, nil
+	}
+	for i, param := range strings.Split(params, ",") {
+		ctype, argName := splitDeclarator(param)
+
+		// A lone type (e.g., "int") has no name
+		if _, ok := lookupType(ctype + " " + argName); ok && !strings.HasSuffix(ctype, "*") {
+			ctype, argName = strings.TrimSpace(ctype+" "+argName), ""
+		}
+		if argName == "" {
+			argName = fmt.Sprintf("arg%d", i)
+		}
+		t, ok := lookupType(ctype)
+		if !ok {
+			return Signature{}, fmt.Errorf("unsupported type %q for %s", ctype, argName)
+		}
+		sig.Arguments = append(sig.Arguments, Argument{Name: argName, CType: normalizeType(ctype), Class: t.Class, Size: t.Size})
+	}
+	Predict(&sig)
+	return sig, nil
+}
+
+// splitDeclarator splits "unsigned long *name" into a type and a name
+func splitDeclarator(decl string) (string, string) {
+	decl = strings.TrimSpace(decl)
+	i := strings.LastIndexAny(decl, " *")
+	if i < 0 {
+		return decl, ""
+	}
+	return strings.TrimSpace(decl[:i+1]), strings.TrimSpace(decl[i+1:])
+}
+
+// Predict fills in argument locations using the x86_64 classification and allocator
+func Predict(sig *Signature) {
+	allocator := x86_64.NewRegisterAllocator()
+	for i, arg := range sig.Arguments {
+		c := file.Component{Name: arg.Name, Class: arg.Class, Size: arg.Size, RawType: rawType(arg)}
+		indirections := int64(0)
+		if arg.Class == "Pointer" {
+			indirections = 1
+		}
+		cls := x86_64.ClassifyType(&c, &indirections)
+		sig.Arguments[i].Location = allocator.GetRegisterString(cls.Lo, cls.Hi, arg.Size, arg.Class)
+	}
+}
+
+// rawType creates the dwarf type the classifier expects for an argument
+func rawType(arg Argument) dwarf.Type {
+	basic := dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: arg.Size, Name: arg.CType}}
+	var t dwarf.Type
+	switch arg.Class {
+	case "Pointer":
+		t = &dwarf.PtrType{CommonType: basic.CommonType, Type: &dwarf.VoidType{}}
+	case "Float":
+		t = &dwarf.FloatType{BasicType: basic}
+	case "Uint":
+		t = &dwarf.UintType{BasicType: basic}
+	case "Char":
+		t = &dwarf.CharType{BasicType: basic}
+	case "Uchar":
+		t = &dwarf.UcharType{BasicType: basic}
+	case "Bool":
+		t = &dwarf.BoolType{BasicType: basic}
+	default:
+		t = &dwarf.IntType{BasicType: basic}
+	}
+	t.Common().Original = t
+	return t
+}
+
+// FromCorpus converts the functions of a loaded corpus, keeping predicted locations.
+// Functions with a parameter we cannot spell in C (or without a location) are skipped.
+func FromCorpus(c *corpus.LoadedCorpus) ([]Signature, []string) {
+	sigs := []Signature{}
+	skipped := []string{}
+	for _, function := range c.Functions {
+		sig := Signature{Name: function.Name, Return: "void"}
+		ok := true
+		for i, param := range function.Parameters {
+			arg, supported := fromParameter(param, i)
+			if !supported {
+				ok = false
+				break
+			}
+			sig.Arguments = append(sig.Arguments, arg)
+		}
+		if ok && len(sig.Arguments) > 0 {
+			sigs = append(sigs, sig)
+		} else if !ok {
+			skipped = append(skipped, function.Name)
+		}
+	}
+	return sigs, skipped
+}
+
+// fromParameter converts a corpus parameter to an argument
+func fromParameter(param descriptor.Parameter, index int) (Argument, bool) {
+	name := param.GetName()
+	if name == "" {
+		name = fmt.Sprintf("arg%d", index)
+	}
+	if param.GetLocation() == "" || param.GetDirection() == "export" {
+		return Argument{}, false
+	}
+	if param.GetClass() == "Pointer" {
+		return Argument{Name: name, CType: "void *", Class: "Pointer", Size: 8, Location: param.GetLocation()}, true
+	}
+
+	// DWARF names complex types "complex float", C wants _Complex
+	ctype := param.GetType()
+	if strings.HasPrefix(ctype, "complex ") {
+		return Argument{}, false
+	}
+	if ctype == "__int128 unsigned" {
+		ctype = "unsigned __int128"
+	}
+	t, ok := lookupType(ctype)
+	if !ok {
+		return Argument{}, false
+	}
+	return Argument{Name: name, CType: ctype, Class: t.Class, Size: t.Size, Location: param.GetLocation()}, true
+}
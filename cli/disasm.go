@@ -3,6 +3,7 @@ package cli
 import (
 	"github.com/DataDrake/cli-ng/v2/cmd"
 	"github.com/vsoch/gosmeagle/corpus"
+	"github.com/vsoch/gosmeagle/parsers/file"
 	"os"
 	"regexp"
 )
@@ -11,7 +12,9 @@ import (
 type DisasmArgs struct {
 	Binary []string `desc:"A binary to dissassemble."`
 }
-type DisasmFlags struct{}
+type DisasmFlags struct {
+	Offline bool `long:"offline" desc:"Only use debug files already in the debuginfod cache (don't ask DEBUGINFOD_URLS)"`
+}
 
 var Disasm = cmd.Sub{
 	Name:  "disasm",
@@ -28,6 +31,8 @@ func init() {
 
 func RunDisasm(r *cmd.Root, c *cmd.Sub) {
 	args := c.Args.(*DisasmArgs)
+	flags := c.Flags.(*DisasmFlags)
+	file.DebuginfodOffline = flags.Offline
 	disasm := corpus.GetDisasm(args.Binary[0])
 	var symRE *regexp.Regexp
 	disasm.Print(os.Stdout, symRE, 0, ^uint64(0), true, true)
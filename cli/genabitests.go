@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/DataDrake/cli-ng/v2/cmd"
+	"github.com/vsoch/gosmeagle/abitest"
+	"github.com/vsoch/gosmeagle/corpus"
+)
+
+// Args and flags for gen-abi-tests
+type GenAbiTestsArgs struct {
+	Input []string `desc:"A corpus json file, or a file of C declarations (one per line)."`
+}
+type GenAbiTestsFlags struct {
+	Out string `long:"out" short:"o" desc:"Directory to write tests to (defaults to abi-tests)"`
+}
+
+// GenAbiTests writes C tests that compare predicted locations to the compiler
+var GenAbiTests = cmd.Sub{
+	Name:  "gen-abi-tests",
+	Alias: "g",
+	Short: "Generate C tests to check predicted parameter locations.",
+	Flags: &GenAbiTestsFlags{},
+	Args:  &GenAbiTestsArgs{},
+	Run:   RunGenAbiTests,
+}
+
+func init() {
+	cmd.Register(&GenAbiTests)
+}
+
+// RunGenAbiTests reads signatures (from a corpus or declarations) and writes tests
+func RunGenAbiTests(r *cmd.Root, c *cmd.Sub) {
+	args := c.Args.(*GenAbiTestsArgs)
+	flags := c.Flags.(*GenAbiTestsFlags)
+
+	outdir := flags.Out
+	if outdir == "" {
+		outdir = "abi-tests"
+	}
+
+	var sigs []abitest.Signature
+	if strings.HasSuffix(args.Input[0], ".json") {
+		loaded := corpus.Load(args.Input[0])
+		var skipped []string
+		sigs, skipped = abitest.FromCorpus(&loaded)
+		for _, name := range skipped {
+			fmt.Printf("Skipping %s, it has parameters we cannot test\n", name)
+		}
+	} else {
+		fd, err := os.Open(args.Input[0])
+		if err != nil {
+			log.Fatalf("Cannot open %s: %s\n", args.Input[0], err)
+		}
+		defer fd.Close()
+		sigs, err = abitest.ParseSignatures(fd)
+		if err != nil {
+			log.Fatalf("Cannot parse %s: %s\n", args.Input[0], err)
+		}
+	}
+
+	err := abitest.Write(outdir, sigs)
+	if err != nil {
+		log.Fatalf("Cannot write tests to %s: %s\n", outdir, err)
+	}
+	fmt.Printf("Wrote tests for %d functions to %s, run make -C %s to check them\n", len(sigs), outdir, outdir)
+}
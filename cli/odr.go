@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/DataDrake/cli-ng/v2/cmd"
+	"github.com/vsoch/gosmeagle/parsers/file"
+)
+
+// Args and flags for odr
+type ODRArgs struct {
+	Binary []string `desc:"A binary to check."`
+}
+type ODRFlags struct {
+	Json    bool `long:"json" desc:"Print results as json"`
+	Offline bool `long:"offline" desc:"Only use debug files already in the debuginfod cache (don't ask DEBUGINFOD_URLS)"`
+}
+
+// ODR finds types defined differently in different compile units
+var ODR = cmd.Sub{
+	Name:  "odr",
+	Alias: "o",
+	Short: "Find types with the same name and a different layout across compile units.",
+	Flags: &ODRFlags{},
+	Args:  &ODRArgs{},
+	Run:   RunODR,
+}
+
+func init() {
+	cmd.Register(&ODR)
+}
+
+// RunODR prints each type with more than one layout
+func RunODR(r *cmd.Root, c *cmd.Sub) {
+	args := c.Args.(*ODRArgs)
+	flags := c.Flags.(*ODRFlags)
+	file.DebuginfodOffline = flags.Offline
+
+	f, err := file.Open(args.Binary[0])
+	if err != nil {
+		log.Fatalf("Cannot open %s: %s\n", args.Binary[0], err)
+	}
+	defer f.Close()
+	dwf, err := f.DWARF()
+	if err != nil {
+		log.Fatalf("Cannot read DWARF from %s: %s\n", args.Binary[0], err)
+	}
+	violations := file.ODRViolations(dwf)
+
+	if flags.Json {
+		out, _ := json.MarshalIndent(violations, "", "    ")
+		fmt.Println(string(out))
+		return
+	}
+	for _, violation := range violations {
+		fmt.Println(violation.Name)
+		for _, definition := range violation.Definitions {
+			fmt.Printf("    %s\n        in %s\n", definition.Layout, strings.Join(definition.CompileUnits, ", "))
+		}
+	}
+	fmt.Printf("%d types with more than one definition\n", len(violations))
+}
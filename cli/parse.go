@@ -3,14 +3,19 @@ package cli
 import (
 	"github.com/DataDrake/cli-ng/v2/cmd"
 	"github.com/vsoch/gosmeagle/corpus"
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"path/filepath"
 )
 
 // Args and flags for generate
 type ParserArgs struct {
-	Binary []string `desc:"A binary to parse."`
+	Binary []string `desc:"A binary to parse, and other binaries or debug files to find type definitions in."`
 }
 type ParserFlags struct {
-	Pretty bool `long:"pretty" desc:"Pretty print the json"`
+	Pretty   bool   `long:"pretty" desc:"Pretty print the json"`
+	DwoPath  string `long:"dwo-path" desc:"Directories to find split DWARF (.dwo and .dwp files) in, separated by :"`
+	DebugDir string `long:"debug-dir" desc:"Directories to find separate debug files in (default /usr/lib/debug), separated by :"`
+	Offline  bool   `long:"offline" desc:"Only use debug files already in the debuginfod cache (don't ask DEBUGINFOD_URLS)"`
 }
 
 // Parser looks at symbols and ABI in Go
@@ -31,6 +36,13 @@ func init() {
 func RunParser(r *cmd.Root, c *cmd.Sub) {
 	args := c.Args.(*ParserArgs)
 	flags := c.Flags.(*ParserFlags)
-	C := corpus.GetCorpus(args.Binary[0])
+	if flags.DwoPath != "" {
+		file.SplitDwarfPath = filepath.SplitList(flags.DwoPath)
+	}
+	if flags.DebugDir != "" {
+		file.DebugDirectories = filepath.SplitList(flags.DebugDir)
+	}
+	file.DebuginfodOffline = flags.Offline
+	C := corpus.GetCorpus(args.Binary[0], args.Binary[1:]...)
 	C.ToJson(flags.Pretty)
 }
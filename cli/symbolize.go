@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDrake/cli-ng/v2/cmd"
+	"github.com/vsoch/gosmeagle/parsers/file"
+)
+
+// Args and flags for symbolize
+type SymbolizeArgs struct {
+	Binary []string `desc:"A binary, and addresses to symbolize (read from stdin if there are none)."`
+}
+type SymbolizeFlags struct {
+	Json     bool   `long:"json" desc:"Print results as json"`
+	DebugDir string `long:"debug-dir" desc:"Directories to find separate debug files in (default /usr/lib/debug), separated by :"`
+	Offline  bool   `long:"offline" desc:"Only use debug files already in the debuginfod cache (don't ask DEBUGINFOD_URLS)"`
+}
+
+// Symbolize finds the function, source line and inlined calls of addresses
+var Symbolize = cmd.Sub{
+	Name:  "symbolize",
+	Alias: "s",
+	Short: "Find the symbol, source line, and inlined functions of addresses.",
+	Flags: &SymbolizeFlags{},
+	Args:  &SymbolizeArgs{},
+	Run:   RunSymbolize,
+}
+
+func init() {
+	cmd.Register(&Symbolize)
+}
+
+// RunSymbolize prints each address with its symbol and frames
+func RunSymbolize(r *cmd.Root, c *cmd.Sub) {
+	args := c.Args.(*SymbolizeArgs)
+	flags := c.Flags.(*SymbolizeFlags)
+	if flags.DebugDir != "" {
+		file.DebugDirectories = filepath.SplitList(flags.DebugDir)
+	}
+	file.DebuginfodOffline = flags.Offline
+
+	f, err := file.Open(args.Binary[0])
+	if err != nil {
+		log.Fatalf("Cannot open %s: %s\n", args.Binary[0], err)
+	}
+	defer f.Close()
+	symbolizer, err := f.NewSymbolizer()
+	if err != nil {
+		log.Fatalf("Cannot symbolize %s: %s\n", args.Binary[0], err)
+	}
+
+	// Addresses are on the command line, or separated by whitespace on stdin
+	addresses := args.Binary[1:]
+	if len(addresses) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Split(bufio.ScanWords)
+		for scanner.Scan() {
+			addresses = append(addresses, scanner.Text())
+		}
+	}
+
+	results := []file.Symbolized{}
+	for _, address := range addresses {
+		pc, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(address), "0x"), 16, 64)
+		if err != nil {
+			log.Printf("Cannot parse address %s\n", address)
+			continue
+		}
+		results = append(results, symbolizer.Symbolize(pc))
+	}
+
+	if flags.Json {
+		out, _ := json.MarshalIndent(results, "", "    ")
+		fmt.Println(string(out))
+		return
+	}
+	for _, result := range results {
+		fmt.Print(result.Address)
+		if result.Symbol != "" {
+			fmt.Printf(" %s+%s", result.Symbol, result.Offset)
+		}
+		fmt.Println()
+		for _, frame := range result.Frames {
+			fmt.Printf("    %s at %s\n", orUnknown(frame.Function), sourceLocation(frame))
+		}
+	}
+}
+
+// sourceLocation formats a file, line and column (like file.c:3:5)
+func sourceLocation(frame file.Frame) string {
+	location := orUnknown(frame.File)
+	if frame.Line > 0 {
+		location += fmt.Sprintf(":%d", frame.Line)
+		if frame.Column > 0 {
+			location += fmt.Sprintf(":%d", frame.Column)
+		}
+	}
+	if frame.Inlined {
+		location += " (inlined)"
+	}
+	return location
+}
+
+// orUnknown returns ?? for an empty name
+func orUnknown(name string) string {
+	if name == "" {
+		return "??"
+	}
+	return name
+}
@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DataDrake/cli-ng/v2/cmd"
+	"github.com/vsoch/gosmeagle/corpus"
+	"github.com/vsoch/gosmeagle/parsers/file"
+)
+
+// Args and flags for verify
+type VerifyArgs struct {
+	Binary []string `desc:"A binary to verify."`
+}
+type VerifyFlags struct {
+	All      bool   `long:"all" desc:"Show parameters that match too"`
+	Json     bool   `long:"json" desc:"Print results as json"`
+	DebugDir string `long:"debug-dir" desc:"Directories to find separate debug files in (default /usr/lib/debug), separated by :"`
+	Offline  bool   `long:"offline" desc:"Only use debug files already in the debuginfod cache (don't ask DEBUGINFOD_URLS)"`
+}
+
+// Verify compares predicted locations to the ones the compiler recorded
+var Verify = cmd.Sub{
+	Name:  "verify",
+	Alias: "vf",
+	Short: "Compare predicted parameter locations with DWARF locations.",
+	Flags: &VerifyFlags{},
+	Args:  &VerifyArgs{},
+	Run:   RunVerify,
+}
+
+func init() {
+	cmd.Register(&Verify)
+}
+
+// RunVerify prints a line per parameter that does not match (or all with --all)
+func RunVerify(r *cmd.Root, c *cmd.Sub) {
+	args := c.Args.(*VerifyArgs)
+	flags := c.Flags.(*VerifyFlags)
+	if flags.DebugDir != "" {
+		file.DebugDirectories = filepath.SplitList(flags.DebugDir)
+	}
+	file.DebuginfodOffline = flags.Offline
+	checks := corpus.Verify(args.Binary[0])
+
+	counts := map[string]int{}
+	shown := []corpus.ParameterCheck{}
+	for _, check := range checks {
+		counts[check.Status]++
+		if flags.All || check.Status != "match" {
+			shown = append(shown, check)
+		}
+	}
+
+	if flags.Json {
+		out, _ := json.MarshalIndent(shown, "", "    ")
+		fmt.Println(string(out))
+		return
+	}
+	for _, check := range shown {
+		line := fmt.Sprintf("%-10s %s %s predicted=%s", check.Status, check.Function, check.Parameter, check.Predicted)
+		if check.Compiler != "" {
+			line += " compiler=" + check.Compiler
+		}
+		if check.Note != "" {
+			line += " (" + check.Note + ")"
+		}
+		fmt.Println(line)
+	}
+	fmt.Printf("%d match, %d mismatch, %d unverified\n", counts["match"], counts["mismatch"], counts["unverified"])
+}
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"github.com/vsoch/gosmeagle/descriptor"
 	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/parsers/goabi"
 	"github.com/vsoch/gosmeagle/parsers/x86_64"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
 	"io/ioutil"
 	"log"
 	"os"
 	"reflect"
+	"sort"
 )
 
 // A corpus holds a library name, a list of Functions and variables
@@ -19,8 +22,9 @@ type Corpus struct {
 	Disasm    *file.Disasm                                `json:"-"`
 }
 
-// Get a corpus from a filename
-func GetCorpus(filename string) Corpus {
+// Get a corpus from a filename, and optionally other binaries or debug
+// files to find the definitions of declared types in
+func GetCorpus(filename string, extra ...string) Corpus {
 
 	corpus := Corpus{Library: filename}
 
@@ -29,6 +33,11 @@ func GetCorpus(filename string) Corpus {
 		log.Fatal(err)
 	}
 	defer f.Close()
+	for _, name := range extra {
+		if err := f.AddDebugInfo(name); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// Note that a a file can produce relocations to print
 	// file.PrintRelocationTable(f.GetRelocations())
@@ -67,7 +76,7 @@ func load(filename string) Corpus {
 	// Read as byte array
 	byteArray, err := ioutil.ReadAll(jsonFile)
 	if err != nil {
-		log.Fatalf("Cannot read %s\n", jsonFile)
+		log.Fatalf("Cannot read %s\n", filename)
 	}
 
 	c := Corpus{}
@@ -98,7 +107,6 @@ func (c *Corpus) Parse(f *file.File) {
 			log.Fatalf("Issue retriving symbols from %s", c.Library)
 		}
 
-		// TODO we don't do anything with call sites here
 		for _, symbol := range symbols {
 
 			switch symbol.GetType() {
@@ -110,6 +118,18 @@ func (c *Corpus) Parse(f *file.File) {
 					c.parseFunction(f, symbol, &entry, true)
 				} else {
 					entry, ok := lookup["functions"][symbol.GetName()]
+
+					// Go ABI0 wrappers share debug info with the function they wrap
+					if wrapped, isWrapper := goabi.WrappedName(symbol.GetName()); !ok && isWrapper {
+						entry, ok = lookup["functions"][wrapped]
+					}
+
+					// Compiler clones (e.g., foo.constprop.0) have debug info at their address
+					if _, isClone := file.CloneOrigin(symbol.GetName()); isClone {
+						if clone, found := lookup["addresses"][file.Address(symbol.GetAddress())]; found {
+							entry, ok = clone, true
+						}
+					}
 					if !ok {
 						continue
 					}
@@ -126,14 +146,57 @@ func (c *Corpus) Parse(f *file.File) {
 			}
 		}
 	}
+	c.parseCallSites(f, lookup)
+}
+
+// parseCallSites adds the calls made by each function, in address order
+func (c *Corpus) parseCallSites(f *file.File, lookup map[string]map[string]file.DwarfEntry) {
+	if f.GoArch() != "amd64" {
+		return
+	}
+
+	callers := []*file.FunctionEntry{}
+	for _, entry := range lookup["offsets"] {
+		if function, ok := entry.(*file.FunctionEntry); ok && len(function.CallSites) > 0 {
+			callers = append(callers, function)
+		}
+	}
+	lowpc := func(function *file.FunctionEntry) uint64 {
+		pc, _ := function.Entry.Val(dwarf.AttrLowpc).(uint64)
+		return pc
+	}
+	sort.Slice(callers, func(i, j int) bool {
+		if lowpc(callers[i]) != lowpc(callers[j]) {
+			return lowpc(callers[i]) < lowpc(callers[j])
+		}
+		return callers[i].Entry.Offset < callers[j].Entry.Offset
+	})
+
+	for _, caller := range callers {
+		for i := range caller.CallSites {
+			loc := map[string]descriptor.LocationDescription{}
+			loc["callsite"] = x86_64.ParseCallSite(caller.Name(), &caller.CallSites[i])
+			c.Locations = append(c.Locations, loc)
+		}
+	}
 }
 
 // parse a dynamic function symbol
 func (c *Corpus) parseFunction(f *file.File, symbol file.Symbol, entry *file.DwarfEntry, isCallSite bool) {
 
+	// Functions compiled by Go (plugins, c-shared) use Go's register ABI
+	if file.IsGo(*entry) && goabi.Supported(f.GoArch()) {
+		newFunction := goabi.ParseFunction(f, symbol, entry, c.Disasm, isCallSite)
+		loc := map[string]descriptor.LocationDescription{}
+		loc["function"] = newFunction
+		c.Locations = append(c.Locations, loc)
+		return
+	}
+
 	switch f.GoArch() {
 	case "amd64":
 		newFunction := x86_64.ParseFunction(f, symbol, entry, c.Disasm, isCallSite)
+		describeFunction(&newFunction, symbol, entry)
 		loc := map[string]descriptor.LocationDescription{}
 		loc["function"] = newFunction
 		c.Locations = append(c.Locations, loc)
@@ -142,6 +205,31 @@ func (c *Corpus) parseFunction(f *file.File, symbol file.Symbol, entry *file.Dwa
 	}
 }
 
+// describeFunction adds where a function is declared (C++ scopes and methods)
+// and what it was cloned from
+func describeFunction(function *descriptor.FunctionDescription, symbol file.Symbol, entry *file.DwarfEntry) {
+	if origin, isClone := file.CloneOrigin(symbol.GetName()); isClone {
+		function.Origin = origin
+		function.Note = "compiler clone of " + origin + ", the ABI may differ (parameters can be removed or split)"
+	}
+	functionEntry, ok := (*entry).(*file.FunctionEntry)
+	if !ok {
+		return
+	}
+	function.QualifiedName = functionEntry.QualifiedName()
+	if params := functionEntry.TemplateParams(); len(params) > 0 {
+		function.Template = file.TemplateName(function.QualifiedName)
+		if function.Template == "" {
+			function.Template = file.TemplateName(functionEntry.Name())
+		}
+		function.TemplateArguments = file.DescribeTemplateParams(params)
+	}
+	if method := functionEntry.Method(); method != nil {
+		function.Method = &descriptor.Method{Class: method.Class, Static: method.Static, Virtual: method.Virtual,
+			Const: method.Const, RefQualifier: method.RefQualifier}
+	}
+}
+
 // parse a global variable
 func (c *Corpus) parseVariable(f *file.File, symbol file.Symbol, entry *file.DwarfEntry) {
 
@@ -11,6 +11,7 @@ import (
 type LoadedCorpus struct {
 	Functions []descriptor.FunctionDescription
 	Variables []descriptor.VariableDescription
+	CallSites []descriptor.CallSiteDescription
 	Library   string
 }
 
@@ -29,6 +30,11 @@ func (c *LoadedCorpus) ToCorpus() *Corpus {
 		newVar["variable"] = entry
 		locs = append(locs, newVar)
 	}
+	for _, entry := range c.CallSites {
+		newCall := map[string]descriptor.LocationDescription{}
+		newCall["callsite"] = entry
+		locs = append(locs, newCall)
+	}
 	return &Corpus{Library: c.Library, Locations: locs}
 }
 
@@ -38,6 +44,7 @@ func Load(filename string) LoadedCorpus {
 	c := load(filename)
 	funcs := []descriptor.FunctionDescription{}
 	vars := []descriptor.VariableDescription{}
+	calls := []descriptor.CallSiteDescription{}
 
 	for _, entry := range c.Locations {
 		function, ok := entry["function"]
@@ -50,11 +57,16 @@ func Load(filename string) LoadedCorpus {
 			newVar := convertVariableDescriptor(variable)
 			vars = append(vars, newVar)
 		}
+		call, ok := entry["callsite"]
+		if ok {
+			calls = append(calls, convertCallSiteDescriptor(call))
+		}
 	}
 
 	corp := LoadedCorpus{Library: filename}
 	corp.Functions = funcs
 	corp.Variables = vars
+	corp.CallSites = calls
 	return corp
 }
 
@@ -66,8 +78,12 @@ func loadParameter(param interface{}) descriptor.Parameter {
 	switch paramClass {
 	case "Pointer":
 		return loadPointer(param)
+	case "Reference":
+		return loadReference(param)
 	case "Struct":
 		return loadStructure(param)
+	case "FunctionPointer":
+		return loadFunctionPointer(param)
 	}
 
 	// Integer, Float
@@ -76,30 +92,67 @@ func loadParameter(param interface{}) descriptor.Parameter {
 
 func loadFunctionParameter(param interface{}) descriptor.Parameter {
 	s := descriptor.FunctionParameter{}
-	mapstructure.Decode(param, &s)
-	sizeInt, err := strconv.ParseInt(param.(map[string]interface{})["size"].(string), 10, 64)
-	if err != nil {
-		log.Fatalf("Error converting string of size to int64: %x", err)
-	}
-	s.Size = sizeInt
+	decode(param, &s)
+	s.Size = loadInt(param, "size")
 	return s
 }
 
 func loadPointer(param interface{}) descriptor.Parameter {
 
 	s := descriptor.PointerParameter{}
-	mapstructure.Decode(param, &s)
-	sizeInt, err := strconv.ParseInt(param.(map[string]interface{})["size"].(string), 10, 64)
-	if err != nil {
-		log.Fatalf("Error converting string of size to int64: %x", err)
+	decode(param, &s)
+	if underlying := param.(map[string]interface{})["underlying_type"]; underlying != nil {
+		s.UnderlyingType = loadParameter(underlying)
 	}
-	indirections, err := strconv.ParseInt(param.(map[string]interface{})["indirections"].(string), 10, 64)
-	s.UnderlyingType = loadParameter(param.(map[string]interface{})["underlying_type"])
-	s.Size = sizeInt
-	s.Indirections = indirections
+	s.Size = loadInt(param, "size")
+	s.Indirections = loadInt(param, "indirections")
 	return s
 }
 
+func loadReference(param interface{}) descriptor.Parameter {
+
+	s := descriptor.ReferenceParameter{}
+	decode(param, &s)
+	if underlying := param.(map[string]interface{})["underlying_type"]; underlying != nil {
+		s.UnderlyingType = loadParameter(underlying)
+	}
+	s.Size = loadInt(param, "size")
+	return s
+}
+
+// loadFunctionPointer loads a function pointer and the signature of the function
+func loadFunctionPointer(param interface{}) descriptor.Parameter {
+
+	s := descriptor.FunctionPointerParameter{}
+	decode(param, &s)
+	s.Parameters = []descriptor.Parameter{}
+	if paramsraw := param.(map[string]interface{})["parameters"]; paramsraw != nil {
+		for _, raw := range paramsraw.([]interface{}) {
+			s.Parameters = append(s.Parameters, loadParameter(raw))
+		}
+	}
+	if result := param.(map[string]interface{})["return"]; result != nil {
+		s.Return = loadParameter(result)
+	}
+	s.Size = loadInt(param, "size")
+	return s
+}
+
+// loadInt reads an integer field, which Smeagle writes as a string and we write as a number
+func loadInt(param interface{}, key string) int64 {
+	switch value := param.(map[string]interface{})[key].(type) {
+	case string:
+		number, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Fatalf("Error converting string of %s to int64: %x", key, err)
+		}
+		return number
+	case float64:
+		return int64(value)
+	}
+	return 0
+}
+
 // convertFunctionDescriptor converts to a function descriptor to
 func convertFunctionDescriptor(item interface{}) descriptor.FunctionDescription {
 	desc := descriptor.FunctionDescription{}
@@ -122,14 +175,11 @@ func convertFunctionDescriptor(item interface{}) descriptor.FunctionDescription
 func loadStructure(param interface{}) descriptor.Parameter {
 
 	s := descriptor.StructureParameter{}
-	mapstructure.Decode(param, &s)
+	decode(param, &s)
 
 	// If we don't reset, the above will load nils
 	s.Fields = []descriptor.Parameter{}
-	sizeInt, err := strconv.ParseInt(param.(map[string]interface{})["size"].(string), 10, 64)
-	if err != nil {
-		log.Fatalf("Error converting string of size to int64: %x", err)
-	}
+	sizeInt := loadInt(param, "size")
 	fieldsraw := param.(map[string]interface{})["fields"]
 	if fieldsraw != nil {
 		fields := fieldsraw.([]interface{})
@@ -148,7 +198,25 @@ func loadStructure(param interface{}) descriptor.Parameter {
 // convertFunctionDescriptor converts to a function descriptor
 func convertVariableDescriptor(item interface{}) descriptor.VariableDescription {
 	desc := descriptor.VariableDescription{}
-	mapstructure.Decode(item, &desc)
+	decode(item, &desc)
 	desc.Type = "Variable"
 	return desc
 }
+
+// convertCallSiteDescriptor converts to a call site descriptor
+func convertCallSiteDescriptor(item interface{}) descriptor.CallSiteDescription {
+	desc := descriptor.CallSiteDescription{}
+	decode(item, &desc)
+	desc.Type = "CallSite"
+	return desc
+}
+
+// decode fills a descriptor from json, matching fields by their json names
+// (e.g., return_pc, or dwarf_location of the annotations of a parameter).
+// Fields that can't be decoded (e.g., a parameter) are loaded by the caller.
+func decode(item interface{}, result interface{}) {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{TagName: "json", Squash: true, Result: result})
+	if err == nil {
+		decoder.Decode(item)
+	}
+}
@@ -0,0 +1,50 @@
+package corpus
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+)
+
+// The annotations of parameters (at any depth) are kept when a corpus is
+// written and loaded again
+func TestLoadKeepsAnnotations(t *testing.T) {
+	register := &descriptor.DwarfLocation{Pieces: []descriptor.DwarfPiece{{Kind: "register", Register: "%rdi"}}}
+	stack := &descriptor.DwarfLocation{Pieces: []descriptor.DwarfPiece{{Kind: "memory", Base: "frame_base", Offset: -24}}}
+
+	params := []descriptor.Parameter{
+		descriptor.FunctionParameter{Name: "n", Type: "long unsigned int", Class: "Uint", Location: "%rdi",
+			Direction: "import", Size: 8, Annotations: descriptor.Annotations{DwarfLocation: register,
+				TypedefChain: []string{"my_size", "size_t"}}},
+		descriptor.PointerParameter{Name: "p", Type: "config *", Class: "Pointer", Location: "%rsi",
+			Direction: "import", Size: 8, Indirections: 1, Annotations: descriptor.Annotations{DwarfLocation: stack},
+			UnderlyingType: descriptor.StructureParameter{Type: "config", Class: "Struct", Size: 4,
+				Annotations: descriptor.Annotations{TypedefChain: []string{"config_t"}},
+				Fields: []descriptor.Parameter{descriptor.FunctionParameter{Name: "verbose", Type: "int",
+					Class: "Int", Size: 4, Annotations: descriptor.Annotations{TypedefChain: []string{"flag"}}}}}},
+	}
+	function := descriptor.FunctionDescription{Name: "configure", Type: "Function", Parameters: params}
+	c := Corpus{Library: "libconfig.so", Locations: []map[string]descriptor.LocationDescription{{"function": function}}}
+
+	out, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "corpus.json")
+	if err := os.WriteFile(filename, out, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := Load(filename)
+	if len(loaded.Functions) != 1 {
+		t.Fatalf("loaded %d functions, want 1", len(loaded.Functions))
+	}
+	want, _ := json.Marshal(params)
+	got, _ := json.Marshal(loaded.Functions[0].Parameters)
+	if string(got) != string(want) {
+		t.Errorf("loaded parameters\n%s\nwant\n%s", got, want)
+	}
+}
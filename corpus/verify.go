@@ -0,0 +1,122 @@
+package corpus
+
+// Verify compares the locations we predict with the ones the compiler
+// recorded in DW_AT_location, evaluated at the first instruction of the
+// function (where the calling convention says arguments are).
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/parsers/goabi"
+	"github.com/vsoch/gosmeagle/parsers/x86_64"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// A ParameterCheck is the result of verifying one parameter
+type ParameterCheck struct {
+	Function  string `json:"function"`
+	Parameter string `json:"parameter"`
+	Predicted string `json:"predicted"`
+	Compiler  string `json:"compiler,omitempty"`
+	Status    string `json:"status"` // match, mismatch, or unverified
+	Note      string `json:"note,omitempty"`
+}
+
+// Verify parses a binary and checks each function parameter against DWARF
+func Verify(filename string) []ParameterCheck {
+
+	f, err := file.Open(filename)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.GoArch() != "amd64" {
+		log.Fatalf("Verify is not supported for %s\n", f.GoArch())
+	}
+
+	c := Corpus{Library: filename}
+	c.Parse(f)
+	lookup := f.ParseDwarf()
+
+	checks := []ParameterCheck{}
+	for _, location := range c.Locations {
+		function, ok := location["function"].(descriptor.FunctionDescription)
+		if !ok {
+			continue
+		}
+
+		// ABI0 wrappers share the DWARF of the function they wrap
+		if _, isWrapper := goabi.WrappedName(function.Name); isWrapper {
+			continue
+		}
+		entry, ok := lookup["functions"][function.Name].(*file.FunctionEntry)
+		if !ok {
+			continue
+		}
+		checks = append(checks, verifyFunction(function, entry)...)
+	}
+	return checks
+}
+
+// verifyFunction checks the parameters of one function
+func verifyFunction(function descriptor.FunctionDescription, entry *file.FunctionEntry) []ParameterCheck {
+	checks := []ParameterCheck{}
+
+	predicted := map[string]string{}
+	for _, param := range function.Parameters {
+		predicted[param.GetName()] = param.GetLocation()
+	}
+
+	lowpc, ok := entry.Entry.Val(dwarf.AttrLowpc).(uint64)
+	if !ok {
+		return checks
+	}
+	frameBase, _ := entry.Data.EvaluateLocation(entry.Entry, dwarf.AttrFrameBase, lowpc)
+
+	for _, param := range entry.Params {
+		name, _ := param.Entry.Val(dwarf.AttrName).(string)
+		location, ok := predicted[name]
+		if name == "" || !ok {
+			continue
+		}
+
+		// Go result parameters are not assigned until the function returns
+		if varParam, _ := param.Entry.Val(dwarf.AttrVarParam).(bool); varParam {
+			continue
+		}
+		check := ParameterCheck{Function: function.Name, Parameter: name, Predicted: location}
+
+		loc, err := entry.Data.EvaluateLocation(param.Entry, dwarf.AttrLocation, lowpc)
+		if err != nil {
+			check.Status = "unverified"
+			check.Note = fmt.Sprintf("%s", err)
+			checks = append(checks, check)
+			continue
+		}
+		compiler, ok := x86_64.EntryLocationString(loc, frameBase)
+		check.Compiler = compiler
+		switch {
+		case !ok:
+			check.Status = "unverified"
+			check.Note = "location is not relative to the entry stack pointer"
+
+		// Without optimization, register arguments are described by their home
+		// in the frame (below the return address), so entry registers are unknown
+		case strings.HasPrefix(compiler, "framebase-"):
+			check.Status = "unverified"
+			check.Note = "parameter is described by its spill slot in the frame"
+
+		case compiler == location:
+			check.Status = "match"
+		default:
+			check.Status = "mismatch"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -1,5 +1,7 @@
 package descriptor
 
+import "strings"
+
 // Sizes are in bytes
 
 // A general interface to support different parameter types
@@ -10,6 +12,10 @@ type Parameter interface {
 	GetLocation() string
 	GetType() string
 	GetDirection() string
+
+	// What the debug information says about where a parameter is, and how it
+	// was declared (see Annotations)
+	GetAnnotations() Annotations
 }
 
 // A General Location description holds a variable or function
@@ -17,138 +23,464 @@ type LocationDescription interface{}
 
 // A function description has a list of parameters
 type FunctionDescription struct {
-	Parameters []Parameter `json:"parameters,omitempty"`
-	Name       string      `json:"name"`
-	Direction  string      `json:"direction,omitempty"`
-	Type       string      `json:"type"`
+	Parameters        []Parameter `json:"parameters,omitempty"`
+	Name              string      `json:"name"`
+	Direction         string      `json:"direction,omitempty"`
+	Type              string      `json:"type"`
+	CallingConvention string      `json:"calling_convention,omitempty"`
+	Wraps             string      `json:"wraps,omitempty"`  // the function an ABI wrapper calls into
+	Origin            string      `json:"origin,omitempty"` // the function a compiler clone was made from
+	Note              string      `json:"note,omitempty"`
+	QualifiedName     string      `json:"qualified_name,omitempty"` // with namespaces and classes
+	Method            *Method     `json:"method,omitempty"`
+
+	// The template (without arguments) and arguments of a specialization
+	Template          string             `json:"template,omitempty"`
+	TemplateArguments []TemplateArgument `json:"template_arguments,omitempty"`
+}
+
+// A CallSiteDescription is a call made from a function in the binary, as the
+// compiler described it (e.g., how an application calls into a library)
+type CallSiteDescription struct {
+	Caller     string             `json:"caller"`
+	Callee     string             `json:"callee,omitempty"`      // the function called, if it is known
+	Indirect   bool               `json:"indirect,omitempty"`    // called through a pointer
+	Target     string             `json:"target,omitempty"`      // where the address of an indirect call is
+	TargetType string             `json:"target_type,omitempty"` // the type of the function called indirectly
+	ReturnPC   string             `json:"return_pc,omitempty"`
+	TailCall   bool               `json:"tail_call,omitempty"`
+	Arguments  []CallSiteArgument `json:"arguments,omitempty"`
+	Type       string             `json:"type"`
+}
+
+// A CallSiteArgument is where an argument is passed at a call site, and its value
+// if the compiler knows it: a constant (value), or how it is computed (e.g.,
+// %rbx+8, or entry(%rdi) for the value a register had when the caller was entered)
+type CallSiteArgument struct {
+	Name       string `json:"name,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Value      string `json:"value,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// A TemplateArgument is a type or value a template was specialized with
+type TemplateArgument struct {
+	Name  string `json:"name,omitempty"`
+	Kind  string `json:"kind"` // type or value
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+// InstantiationKey identifies a template specialization by the template and
+// its arguments (e.g., lib::Box<int, 4>), to match specializations across
+// libraries. Without arguments, it is the name.
+func InstantiationKey(template string, args []TemplateArgument) string {
+	if len(args) == 0 {
+		return template
+	}
+	values := []string{}
+	for _, arg := range args {
+		if arg.Kind == "value" {
+			values = append(values, arg.Value)
+		} else {
+			values = append(values, arg.Type)
+		}
+	}
+	return template + "<" + strings.Join(values, ", ") + ">"
+}
+
+// A Method describes a C++ member function
+type Method struct {
+	Class        string `json:"class"`
+	Static       bool   `json:"static,omitempty"`
+	Virtual      bool   `json:"virtual,omitempty"`
+	Const        bool   `json:"const,omitempty"`
+	RefQualifier string `json:"ref_qualifier,omitempty"` // & or &&
+}
+
+// A FunctionPointerParameter is a pointer to a function (e.g., a callback), with
+// its signature. The library calls the function, so the direction of its
+// parameters and return value is the opposite of a function it exports.
+type FunctionPointerParameter struct {
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Size        int64       `json:"size,omitempty"`
+	Parameters  []Parameter `json:"parameters,omitempty"`
+	Return      Parameter   `json:"return,omitempty"`
+	Variadic    bool        `json:"variadic,omitempty"`
 }
 
 type FunctionParameter struct {
-	Name      string `json:"name,omitempty"`
-	Type      string `json:"type,omitempty"`
-	Class     string `json:"class,omitempty"`
-	Direction string `json:"direction,omitempty"`
-	Location  string `json:"location,omitempty"`
-	Size      int64  `json:"size,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Size        int64 `json:"size,omitempty"`
 }
 
 // All types can return a size and name
-func (f FunctionParameter) GetSize() int64  { return f.Size }
-func (f StructureParameter) GetSize() int64 { return f.Size }
-func (f PointerParameter) GetSize() int64   { return f.Size }
-func (f ArrayParameter) GetSize() int64     { return f.Size }
-func (f QualifiedParameter) GetSize() int64 { return f.Size }
-func (f BasicParameter) GetSize() int64     { return f.Size }
-func (f EnumParameter) GetSize() int64      { return f.Size }
-
-func (f FunctionParameter) GetClass() string  { return f.Class }
-func (f StructureParameter) GetClass() string { return f.Class }
-func (f PointerParameter) GetClass() string   { return f.Class }
-func (f ArrayParameter) GetClass() string     { return f.Class }
-func (f QualifiedParameter) GetClass() string { return f.Class }
-func (f BasicParameter) GetClass() string     { return f.Class }
-func (f EnumParameter) GetClass() string      { return f.Class }
-
-func (f FunctionParameter) GetName() string  { return f.Name }
-func (f StructureParameter) GetName() string { return f.Name }
-func (f PointerParameter) GetName() string   { return f.Name }
-func (f ArrayParameter) GetName() string     { return f.Name }
-func (f QualifiedParameter) GetName() string { return f.Name }
-func (f BasicParameter) GetName() string     { return f.Name }
-func (f EnumParameter) GetName() string      { return f.Name }
-
-func (f FunctionParameter) GetLocation() string  { return f.Location }
-func (f StructureParameter) GetLocation() string { return f.Location }
-func (f PointerParameter) GetLocation() string   { return f.Location }
-func (f ArrayParameter) GetLocation() string     { return f.Location }
-func (f QualifiedParameter) GetLocation() string { return f.Location }
-func (f BasicParameter) GetLocation() string     { return f.Location }
-func (f EnumParameter) GetLocation() string      { return f.Location }
-
-func (f FunctionParameter) GetType() string  { return f.Type }
-func (f StructureParameter) GetType() string { return f.Type }
-func (f PointerParameter) GetType() string   { return f.Type }
-func (f ArrayParameter) GetType() string     { return f.Type }
-func (f QualifiedParameter) GetType() string { return f.Type }
-func (f BasicParameter) GetType() string     { return f.Type }
-func (f EnumParameter) GetType() string      { return f.Type }
-
-func (f FunctionParameter) GetDirection() string  { return f.Direction }
-func (f StructureParameter) GetDirection() string { return f.Direction }
-func (f PointerParameter) GetDirection() string   { return f.Direction }
-func (f ArrayParameter) GetDirection() string     { return f.Direction }
-func (f QualifiedParameter) GetDirection() string { return f.Direction }
-func (f BasicParameter) GetDirection() string     { return f.Direction }
-func (f EnumParameter) GetDirection() string      { return f.Direction }
+func (f FunctionParameter) GetSize() int64        { return f.Size }
+func (f StructureParameter) GetSize() int64       { return f.Size }
+func (f PointerParameter) GetSize() int64         { return f.Size }
+func (f ArrayParameter) GetSize() int64           { return f.Size }
+func (f QualifiedParameter) GetSize() int64       { return f.Size }
+func (f BasicParameter) GetSize() int64           { return f.Size }
+func (f EnumParameter) GetSize() int64            { return f.Size }
+func (f TaggedUnionParameter) GetSize() int64     { return f.Size }
+func (f MemberPointerParameter) GetSize() int64   { return f.Size }
+func (f ReferenceParameter) GetSize() int64       { return f.Size }
+func (f FunctionPointerParameter) GetSize() int64 { return f.Size }
+
+func (f FunctionParameter) GetClass() string        { return f.Class }
+func (f StructureParameter) GetClass() string       { return f.Class }
+func (f PointerParameter) GetClass() string         { return f.Class }
+func (f ArrayParameter) GetClass() string           { return f.Class }
+func (f QualifiedParameter) GetClass() string       { return f.Class }
+func (f BasicParameter) GetClass() string           { return f.Class }
+func (f EnumParameter) GetClass() string            { return f.Class }
+func (f TaggedUnionParameter) GetClass() string     { return f.Class }
+func (f MemberPointerParameter) GetClass() string   { return f.Class }
+func (f ReferenceParameter) GetClass() string       { return f.Class }
+func (f FunctionPointerParameter) GetClass() string { return f.Class }
+
+func (f FunctionParameter) GetName() string        { return f.Name }
+func (f StructureParameter) GetName() string       { return f.Name }
+func (f PointerParameter) GetName() string         { return f.Name }
+func (f ArrayParameter) GetName() string           { return f.Name }
+func (f QualifiedParameter) GetName() string       { return f.Name }
+func (f BasicParameter) GetName() string           { return f.Name }
+func (f EnumParameter) GetName() string            { return f.Name }
+func (f TaggedUnionParameter) GetName() string     { return f.Name }
+func (f MemberPointerParameter) GetName() string   { return f.Name }
+func (f ReferenceParameter) GetName() string       { return f.Name }
+func (f FunctionPointerParameter) GetName() string { return f.Name }
+
+func (f FunctionParameter) GetLocation() string        { return f.Location }
+func (f StructureParameter) GetLocation() string       { return f.Location }
+func (f PointerParameter) GetLocation() string         { return f.Location }
+func (f ArrayParameter) GetLocation() string           { return f.Location }
+func (f QualifiedParameter) GetLocation() string       { return f.Location }
+func (f BasicParameter) GetLocation() string           { return f.Location }
+func (f EnumParameter) GetLocation() string            { return f.Location }
+func (f TaggedUnionParameter) GetLocation() string     { return f.Location }
+func (f MemberPointerParameter) GetLocation() string   { return f.Location }
+func (f ReferenceParameter) GetLocation() string       { return f.Location }
+func (f FunctionPointerParameter) GetLocation() string { return f.Location }
+
+func (f FunctionParameter) GetType() string        { return f.Type }
+func (f StructureParameter) GetType() string       { return f.Type }
+func (f PointerParameter) GetType() string         { return f.Type }
+func (f ArrayParameter) GetType() string           { return f.Type }
+func (f QualifiedParameter) GetType() string       { return f.Type }
+func (f BasicParameter) GetType() string           { return f.Type }
+func (f EnumParameter) GetType() string            { return f.Type }
+func (f TaggedUnionParameter) GetType() string     { return f.Type }
+func (f MemberPointerParameter) GetType() string   { return f.Type }
+func (f ReferenceParameter) GetType() string       { return f.Type }
+func (f FunctionPointerParameter) GetType() string { return f.Type }
+
+func (f FunctionParameter) GetDirection() string        { return f.Direction }
+func (f StructureParameter) GetDirection() string       { return f.Direction }
+func (f PointerParameter) GetDirection() string         { return f.Direction }
+func (f ArrayParameter) GetDirection() string           { return f.Direction }
+func (f QualifiedParameter) GetDirection() string       { return f.Direction }
+func (f BasicParameter) GetDirection() string           { return f.Direction }
+func (f EnumParameter) GetDirection() string            { return f.Direction }
+func (f TaggedUnionParameter) GetDirection() string     { return f.Direction }
+func (f MemberPointerParameter) GetDirection() string   { return f.Direction }
+func (f ReferenceParameter) GetDirection() string       { return f.Direction }
+func (f FunctionPointerParameter) GetDirection() string { return f.Direction }
 
 type StructureParameter struct {
-	Name      string      `json:"name,omitempty"`
-	Type      string      `json:"type,omitempty"`
-	Class     string      `json:"class,omitempty"`
-	Size      int64       `json:"size,omitempty"`
-	Direction string      `json:"direction,omitempty"`
-	Location  string      `json:"location,omitempty"`
-	Fields    []Parameter `json:"fields,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Fields      []Parameter  `json:"fields,omitempty"`
+	Bases       []BaseClass  `json:"bases,omitempty"`
+	Vtable      []VtableSlot `json:"vtable,omitempty"`
+
+	// Declared, but there is no definition (e.g., a handle the library keeps private)
+	Opaque bool `json:"opaque,omitempty"`
+
+	Template          string             `json:"template,omitempty"`
+	TemplateArguments []TemplateArgument `json:"template_arguments,omitempty"`
+}
+
+// A BaseClass is a C++ base class of a structure
+type BaseClass struct {
+	Type    string `json:"type"`
+	Offset  int64  `json:"offset"`
+	Virtual bool   `json:"virtual,omitempty"` // the offset of a virtual base is in the vtable
+	Access  string `json:"access"`
+}
+
+// A VtableSlot is the virtual method called through one vtable entry
+type VtableSlot struct {
+	Slot        int64  `json:"slot"`
+	Name        string `json:"name,omitempty"`
+	LinkageName string `json:"linkage_name,omitempty"`
+	Deleting    bool   `json:"deleting,omitempty"` // the deleting destructor
 }
 
 type PointerParameter struct {
-	Name           string    `json:"name,omitempty"`
-	Type           string    `json:"type,omitempty"`
-	Class          string    `json:"class,omitempty"`
-	Direction      string    `json:"direction,omitempty"`
-	Location       string    `json:"location,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Class          string `json:"class,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	Location       string `json:"location,omitempty"`
+	Annotations    `mapstructure:",squash"`
 	Size           int64     `json:"size,omitempty"`
 	UnderlyingType Parameter `json:"underlying_type,omitempty"`
 	Indirections   int64     `json:"indirections,omitempty"`
 }
 
+// A ReferenceParameter is a C++ reference, which is passed as a pointer
+type ReferenceParameter struct {
+	Name           string `json:"name,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Class          string `json:"class,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	Location       string `json:"location,omitempty"`
+	Annotations    `mapstructure:",squash"`
+	Size           int64     `json:"size,omitempty"`
+	Rvalue         bool      `json:"rvalue,omitempty"`
+	UnderlyingType Parameter `json:"underlying_type,omitempty"`
+}
+
+// A MemberPointerParameter is a C++ pointer to a data member (an offset) or
+// member function (a function pointer and adjustment, in two eightbytes)
+type MemberPointerParameter struct {
+	Name           string `json:"name,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Class          string `json:"class,omitempty"`
+	Direction      string `json:"direction,omitempty"`
+	Location       string `json:"location,omitempty"`
+	Annotations    `mapstructure:",squash"`
+	Size           int64  `json:"size,omitempty"`
+	ContainingType string `json:"containing_type,omitempty"`
+	Function       bool   `json:"function,omitempty"`
+}
+
 type ArrayParameter struct {
-	Name      string    `json:"name,omitempty"`
-	Type      string    `json:"type,omitempty"`
-	Class     string    `json:"class,omitempty"`
-	Size      int64     `json:"size,omitempty"`
-	Length    int64     `json:"count,omitempty"`
-	Location  string    `json:"location,omitempty"`
-	Direction string    `json:"direction,omitempty"`
-	ItemType  Parameter `json:"items_type,omitemtpy"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Length      int64  `json:"count,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Direction   string    `json:"direction,omitempty"`
+	ItemType    Parameter `json:"items_type,omitemtpy"`
+
+	// Arrays with a shape only known at run time (e.g., Fortran assumed shape)
+	Dynamic      bool             `json:"dynamic,omitempty"`
+	Bounds       []ArrayBound     `json:"bounds,omitempty"`
+	DataLocation string           `json:"data_location,omitempty"`
+	Descriptor   *ArrayDescriptor `json:"descriptor,omitempty"`
+}
+
+// An ArrayBound describes one dimension of an array. Each value is a constant,
+// a field of the array descriptor (e.g., descriptor+48), or "runtime".
+type ArrayBound struct {
+	Lower  string `json:"lower,omitempty"`
+	Upper  string `json:"upper,omitempty"`
+	Count  string `json:"count,omitempty"`
+	Stride string `json:"stride,omitempty"`
+}
+
+// An ArrayDescriptor is the layout of the structure passed for an array whose
+// shape is known at run time: a C descriptor (CFI_cdesc_t, ISO_Fortran_binding.h)
+// or the gfortran descriptor
+type ArrayDescriptor struct {
+	Kind   string            `json:"kind"` // CFI or gfortran
+	Rank   int64             `json:"rank"` // -1 for assumed rank
+	Size   int64             `json:"size,omitempty"`
+	Fields []DescriptorField `json:"fields"`
+}
+
+// A DescriptorField is one field of an array descriptor
+type DescriptorField struct {
+	Name   string `json:"name"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
 }
 
 type EnumParameter struct {
-	Name      string           `json:"name,omitempty"`
-	Type      string           `json:"type,omitempty"`
-	Class     string           `json:"class,omitempty"`
-	Size      int64            `json:"size,omitempty"`
-	Location  string           `json:"location,omitempty"`
-	Length    int              `json:"count,omitempty"`
-	Direction string           `json:"direction,omitempty"`
-	Constants map[string]int64 `json:"constants,omitemtpy"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Length      int              `json:"count,omitempty"`
+	Direction   string           `json:"direction,omitempty"`
+	Constants   map[string]int64 `json:"constants,omitemtpy"`
+}
+
+// A TaggedUnionParameter is a discriminated union (e.g., a Rust enum), where
+// the discriminant says which variant is present
+type TaggedUnionParameter struct {
+	Name         string `json:"name,omitempty"`
+	Type         string `json:"type,omitempty"`
+	Class        string `json:"class,omitempty"`
+	Size         int64  `json:"size,omitempty"`
+	Location     string `json:"location,omitempty"`
+	Annotations  `mapstructure:",squash"`
+	Direction    string        `json:"direction,omitempty"`
+	Discriminant *Discriminant `json:"discriminant,omitempty"`
+	Variants     []Variant     `json:"variants,omitempty"`
+
+	// Discriminant values stored in a niche (unused values) of another variant,
+	// e.g., None is a null pointer in Option<&T>
+	NicheValues []int64 `json:"niche_values,omitempty"`
+
+	Template          string             `json:"template,omitempty"`
+	TemplateArguments []TemplateArgument `json:"template_arguments,omitempty"`
+}
+
+// A Discriminant is where a tagged union keeps the value that selects a variant
+type Discriminant struct {
+	Type   string `json:"type"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+}
+
+// A Variant is one case of a tagged union
+type Variant struct {
+	Name    string      `json:"name,omitempty"`
+	Values  []int64     `json:"values,omitempty"` // empty for the default variant
+	Ranges  [][2]int64  `json:"ranges,omitempty"` // low and high discriminant values
+	Default bool        `json:"default,omitempty"`
+	Fields  []Parameter `json:"fields,omitempty"`
 }
 
 // QualifiedParameter and BasicParameter are the same, but we are modeling after debug/dwarf
 type QualifiedParameter struct {
-	Name      string `json:"name,omitempty"`
-	Class     string `json:"class,omitempty"`
-	Type      string `json:"type,omitempty"`
-	Direction string `json:"direction,omitempty"`
-	Location  string `json:"location,omitempty"`
-	Size      int64  `json:"size,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Direction   string `json:"direction,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Size        int64 `json:"size,omitempty"`
 }
 
 type BasicParameter struct {
-	Name      string `json:"name,omitempty"`
-	Type      string `json:"type,omitempty"`
-	Class     string `json:"class,omitempty"`
-	Location  string `json:"location,omitempty"`
-	Direction string `json:"direction,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Type        string `json:"type,omitempty"`
+	Class       string `json:"class,omitempty"`
+	Location    string `json:"location,omitempty"`
+	Annotations `mapstructure:",squash"`
+	Direction   string `json:"direction,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+// A DwarfLocation is where the compiler says a value is (DW_AT_location)
+type DwarfLocation struct {
+	Pieces []DwarfPiece `json:"pieces"`
+}
+
+// A DwarfPiece is where some (or all) of a value is, see dwarf.Piece
+type DwarfPiece struct {
+	Kind      string `json:"kind"`               // register, memory, value, implicit_value, implicit_pointer, optimized_out
+	Register  string `json:"register,omitempty"` // the register holding the value, or the base of an address
+	Base      string `json:"base,omitempty"`     // register, frame_base, cfa, entry_value, tls, address, unknown
+	Offset    int64  `json:"offset,omitempty"`
+	Value     string `json:"value,omitempty"` // hex bytes of an implicit value
 	Size      int64  `json:"size,omitempty"`
+	BitSize   int64  `json:"bit_size,omitempty"`
+	BitOffset int64  `json:"bit_offset,omitempty"`
+}
+
+// Annotations are what the debug information says about a parameter besides
+// its type, shared by every kind of parameter
+type Annotations struct {
+	DwarfLocation *DwarfLocation `json:"dwarf_location,omitempty"`
+	TypedefChain  []string       `json:"typedef_chain,omitempty"` // typedefs it was declared with (outermost first)
+}
+
+// GetAnnotations returns the annotations of a parameter
+func (a Annotations) GetAnnotations() Annotations { return a }
+
+// WithDwarfLocation returns a copy of a parameter with the compiler's location
+func WithDwarfLocation(p Parameter, loc *DwarfLocation) Parameter {
+	if p == nil {
+		return p
+	}
+	annotations := p.GetAnnotations()
+	annotations.DwarfLocation = loc
+	return withAnnotations(p, annotations)
+}
+
+// WithTypedefChain returns a copy of a parameter with the names of the typedefs
+// it was declared with (outermost first)
+func WithTypedefChain(p Parameter, chain []string) Parameter {
+	if p == nil {
+		return p
+	}
+	annotations := p.GetAnnotations()
+	annotations.TypedefChain = chain
+	return withAnnotations(p, annotations)
+}
+
+// withAnnotations returns a copy of a parameter with other annotations (a
+// parameter defined outside of this package is returned as it is)
+func withAnnotations(p Parameter, a Annotations) Parameter {
+	switch param := p.(type) {
+	case FunctionParameter:
+		param.Annotations = a
+		return param
+	case StructureParameter:
+		param.Annotations = a
+		return param
+	case PointerParameter:
+		param.Annotations = a
+		return param
+	case ArrayParameter:
+		param.Annotations = a
+		return param
+	case QualifiedParameter:
+		param.Annotations = a
+		return param
+	case BasicParameter:
+		param.Annotations = a
+		return param
+	case EnumParameter:
+		param.Annotations = a
+		return param
+	case TaggedUnionParameter:
+		param.Annotations = a
+		return param
+	case MemberPointerParameter:
+		param.Annotations = a
+		return param
+	case ReferenceParameter:
+		param.Annotations = a
+		return param
+	case FunctionPointerParameter:
+		param.Annotations = a
+		return param
+	}
+	return p
 }
 
 // A Variable description is general and can also describe an underlying type
 // TODO should there be location here?
 type VariableDescription struct {
-	Name      string `json:"name,omitempty"`
-	Class     string `json:"class,omitempty"`
-	Type      string `json:"type,omitempty"`
-	Size      int64  `json:"size"`
-	Direction string `json:"direction,omitempty"`
+	Name          string         `json:"name,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	Type          string         `json:"type,omitempty"`
+	Size          int64          `json:"size"`
+	Direction     string         `json:"direction,omitempty"`
+	DwarfLocation *DwarfLocation `json:"dwarf_location,omitempty"`
 }
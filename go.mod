@@ -1,9 +1,11 @@
 module github.com/vsoch/gosmeagle
 
-go 1.13
+go 1.26.0
 
 require (
-	github.com/DataDrake/cli-ng/v2 v2.0.2 // indirect
-	github.com/mitchellh/mapstructure v1.4.2 // indirect
-	golang.org/x/arch v0.0.0-20210901143047-ebb09ed340f1 // indirect
+	github.com/DataDrake/cli-ng/v2 v2.0.2
+	github.com/klauspost/compress v1.18.0
+	github.com/mitchellh/mapstructure v1.4.2
+	github.com/ulikunitz/xz v0.5.15
+	golang.org/x/arch v0.31.0
 )
@@ -0,0 +1,213 @@
+package file
+
+import (
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// A call site (DW_TAG_call_site, or DW_TAG_GNU_call_site before DWARF 5) is a
+// call made from a function. It can say what is called (or where the address
+// of an indirect call is), where it returns to, if it is a tail call, and for
+// each argument, where it is passed and the value, if the compiler knows it.
+
+// GNU extensions for call sites, from before DWARF 5
+const (
+	attrGNUCallSiteValue  dwarf.Attr = 0x2111
+	attrGNUCallSiteTarget dwarf.Attr = 0x2113
+	attrGNUTailCall       dwarf.Attr = 0x2115
+)
+
+// Callee returns the name of the function called (the linkage name if there
+// is one), or an empty string for an indirect call
+func (cs *CallSite) Callee() string {
+	for _, entry := range cs.callee() {
+		if name, ok := entry.Val(dwarf.AttrLinkageName).(string); ok {
+			return name
+		}
+		if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// callee returns the entry of the function called, followed by the entries it
+// refers to in turn (e.g., the abstract instance of a method)
+func (cs *CallSite) callee() []*dwarf.Entry {
+	origin, ok := cs.Entry.Val(dwarf.AttrCallOrigin).(dwarf.Offset)
+	if !ok {
+		origin, ok = cs.Entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+	}
+	if !ok || cs.Data == nil {
+		return nil
+	}
+
+	entries := []*dwarf.Entry{}
+	reader := cs.Data.Reader()
+	for depth := 0; depth < maxOriginDepth; depth++ {
+		reader.Seek(origin)
+		entry, err := reader.Next()
+		if err != nil || entry == nil {
+			break
+		}
+		entries = append(entries, entry)
+		if origin, ok = entry.Val(dwarf.AttrSpecification).(dwarf.Offset); !ok {
+			if origin, ok = entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); !ok {
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// CalleeType returns the type of the function called, from its declaration
+// (or for an indirect call, the type on the call site), or nil if unknown
+func (cs *CallSite) CalleeType() *dwarf.FuncType {
+	if function, ok := cs.TargetType().(*dwarf.FuncType); ok {
+		return function
+	}
+
+	// The parameters are children of the first entry that has them
+	for _, entry := range cs.callee() {
+		if !entry.Children {
+			continue
+		}
+		function := &dwarf.FuncType{}
+		function.ReturnType, _ = GetUnderlyingType(entry, cs.Data)
+		reader := cs.Data.Reader()
+		reader.Seek(entry.Offset)
+		reader.Next()
+		for child, err := reader.Next(); child != nil && err == nil && child.Tag != 0; child, err = reader.Next() {
+			switch child.Tag {
+			case dwarf.TagFormalParameter:
+				t, err := GetUnderlyingType(child, cs.Data)
+				if err != nil || t == nil {
+					return nil
+				}
+				function.ParamType = append(function.ParamType, t)
+			case dwarf.TagUnspecifiedParameters:
+				function.ParamType = append(function.ParamType, &dwarf.DotDotDotType{})
+			}
+			if child.Children {
+				reader.SkipChildren()
+			}
+		}
+		return function
+	}
+	return nil
+}
+
+// ReturnPC returns the address the call returns to (DW_AT_call_return_pc, or
+// DW_AT_low_pc of a GNU call site)
+func (cs *CallSite) ReturnPC() (uint64, bool) {
+	if pc, ok := cs.Entry.Val(dwarf.AttrCallReturnPC).(uint64); ok {
+		return pc, true
+	}
+	pc, ok := cs.Entry.Val(dwarf.AttrLowpc).(uint64)
+	return pc, ok
+}
+
+// TailCall determines if the call is a jump that does not return to the caller
+func (cs *CallSite) TailCall() bool {
+	if tail, _ := cs.Entry.Val(dwarf.AttrCallTailCall).(bool); tail {
+		return true
+	}
+	tail, _ := cs.Entry.Val(attrGNUTailCall).(bool)
+	return tail
+}
+
+// Target returns where the address of an indirect call is, if the compiler said
+func (cs *CallSite) Target() *dwarf.Location {
+	for _, attr := range []dwarf.Attr{dwarf.AttrCallTarget, attrGNUCallSiteTarget} {
+		if cs.Entry.Val(attr) == nil || cs.Data == nil {
+			continue
+		}
+		if loc, err := cs.Data.EvaluateLocation(&cs.Entry, attr, 0); err == nil {
+			return loc
+		}
+	}
+	return nil
+}
+
+// TargetType returns the type of the function called (DWARF 5 allows it on
+// a call site, for an indirect call), or nil
+func (cs *CallSite) TargetType() dwarf.Type {
+	if cs.Data == nil || cs.Entry.Val(dwarf.AttrType) == nil {
+		return nil
+	}
+	t, err := GetUnderlyingType(&cs.Entry, cs.Data)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+// ArgumentName returns the name of the parameter of the function called
+// that an argument is for, if the compiler said
+func (cs *CallSite) ArgumentName(param *dwarf.Entry) string {
+	entry := cs.parameter(param)
+	if entry == nil {
+		return ""
+	}
+	name, _ := entry.Val(dwarf.AttrName).(string)
+	return name
+}
+
+// ArgumentType returns the type of the parameter of the function called that
+// an argument is for, if the compiler said (or nil)
+func (cs *CallSite) ArgumentType(param *dwarf.Entry) dwarf.Type {
+	entry := cs.parameter(param)
+	if entry == nil {
+		return nil
+	}
+	t, err := GetUnderlyingType(entry, cs.Data)
+	if err != nil {
+		return nil
+	}
+	return t
+}
+
+// parameter returns the parameter of the function called that an argument
+// is for (DW_AT_call_parameter), or nil
+func (cs *CallSite) parameter(param *dwarf.Entry) *dwarf.Entry {
+	origin, ok := param.Val(dwarf.AttrCallParameter).(dwarf.Offset)
+	if !ok {
+		origin, ok = param.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+	}
+	if !ok || cs.Data == nil {
+		return nil
+	}
+	reader := cs.Data.Reader()
+	reader.Seek(origin)
+	entry, err := reader.Next()
+	if err != nil {
+		return nil
+	}
+	return entry
+}
+
+// ArgumentLocation returns where an argument is passed (at the call)
+func (cs *CallSite) ArgumentLocation(param *dwarf.Entry) *dwarf.Location {
+	if cs.Data == nil {
+		return nil
+	}
+	loc, err := cs.Data.EvaluateLocation(param, dwarf.AttrLocation, 0)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// ArgumentValue returns the value an argument has at the call, computed by
+// an expression (DW_AT_call_value) that is evaluated like a location: the
+// value is the "address" (e.g., a constant, or relative to a register)
+func (cs *CallSite) ArgumentValue(param *dwarf.Entry) *dwarf.Location {
+	for _, attr := range []dwarf.Attr{dwarf.AttrCallValue, attrGNUCallSiteValue} {
+		if param.Val(attr) == nil || cs.Data == nil {
+			continue
+		}
+		if loc, err := cs.Data.EvaluateLocation(param, attr, 0); err == nil {
+			return loc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package file
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Distributions strip binaries and install the debug information separately,
+// found by the build ID of the binary (.build-id/ab/cdef....debug) or by the
+// name in .gnu_debuglink (next to the binary, or under a debug directory at
+// the path of the binary). Symbols are still read from the binary.
+
+// DebugDirectories are where separate debug files are installed
+var DebugDirectories = []string{"/usr/lib/debug"}
+
+// findDebugFile finds and opens the separate debug file of a binary, or
+// returns nil if there is none
+func findDebugFile(binary string, data rawFile) *File {
+
+	// The build ID of the debug file has to match
+	if id := data.BuildID(); len(id) > 1 {
+		name := hex.EncodeToString(id)
+		for _, dir := range DebugDirectories {
+			path := filepath.Join(dir, ".build-id", name[:2], name[2:]+".debug")
+			if debug := openDebugFile(path); debug != nil {
+				if bytes.Equal(debug.Entries[0].data.BuildID(), id) {
+					return debug
+				}
+				debug.Close()
+			}
+		}
+	}
+
+	// The CRC of the file named by .gnu_debuglink has to match
+	link, crc, ok := data.DebugLink()
+	if !ok {
+		return nil
+	}
+	dir, err := filepath.Abs(filepath.Dir(binary))
+	if err != nil {
+		dir = filepath.Dir(binary)
+	}
+	candidates := []string{filepath.Join(dir, link), filepath.Join(dir, ".debug", link)}
+	for _, debugDir := range DebugDirectories {
+		candidates = append(candidates, filepath.Join(debugDir, dir, link))
+	}
+	for _, path := range candidates {
+		if sameFile(path, binary) || !exists(path) {
+			continue
+		}
+		if sum, err := fileCRC(path); err != nil || sum != crc {
+			continue
+		}
+		if debug := openDebugFile(path); debug != nil {
+			return debug
+		}
+	}
+	return nil
+}
+
+// openDebugFile opens a file if it has debug information
+func openDebugFile(path string) *File {
+	if !exists(path) {
+		return nil
+	}
+	debug, err := open(path)
+	if err != nil {
+		return nil
+	}
+	if !debug.Entries[0].data.HasDwarf() {
+		debug.Close()
+		return nil
+	}
+	return debug
+}
+
+// fileCRC computes the CRC (used by .gnu_debuglink) of a file
+func fileCRC(path string) (uint32, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	hash := crc32.NewIEEE()
+	if _, err := io.Copy(hash, f); err != nil {
+		return 0, err
+	}
+	return hash.Sum32(), nil
+}
+
+// sameFile determines if two paths are the same file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// DebugFile returns the name of the separate debug file that debug information
+// is read from, or an empty string if it is read from the file itself
+func (f *File) DebugFile() string {
+	if f.debug == nil {
+		return ""
+	}
+	return f.debug.handle.Name()
+}
@@ -0,0 +1,153 @@
+package file
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A debuginfod server has the debug information (/buildid/<id>/debuginfo) and
+// the executable (/buildid/<id>/executable) of a build ID. Like the elfutils
+// client, the servers are in DEBUGINFOD_URLS (separated by spaces), and files
+// are kept in a cache (DEBUGINFOD_CACHE_PATH, or debuginfod_client in the user
+// cache directory), so they are only downloaded once.
+
+// DebuginfodURLs are the servers to ask, in order
+var DebuginfodURLs = strings.Fields(os.Getenv("DEBUGINFOD_URLS"))
+
+// DebuginfodCache is the directory files are cached in (by build ID)
+var DebuginfodCache = debuginfodCache()
+
+// DebuginfodTimeout is how long to wait for a server to connect and to answer
+// (DEBUGINFOD_TIMEOUT, in seconds). A large file can take longer to download.
+var DebuginfodTimeout = debuginfodTimeout()
+
+// DebuginfodOffline only uses files that are already in the cache
+var DebuginfodOffline = false
+
+// debuginfodCache returns the default cache directory
+func debuginfodCache() string {
+	if path := os.Getenv("DEBUGINFOD_CACHE_PATH"); path != "" {
+		return path
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "debuginfod_client")
+}
+
+// debuginfodTimeout returns the default timeout
+func debuginfodTimeout() time.Duration {
+	if seconds, err := strconv.Atoi(os.Getenv("DEBUGINFOD_TIMEOUT")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return 90 * time.Second
+}
+
+// debuginfodFile opens the debuginfo or executable of a build ID, from the
+// cache or a server, or returns nil if there is none
+func debuginfodFile(id []byte, kind string) *File {
+	path := debuginfod(id, kind)
+	if path == "" {
+		return nil
+	}
+	f, err := open(path)
+	if err != nil {
+		log.Printf("Cannot open %s from debuginfod: %s\n", path, err)
+		return nil
+	}
+
+	// A file that doesn't match (or has nothing we need) isn't used
+	data := f.Entries[0].data
+	if !bytes.Equal(data.BuildID(), id) || (kind == "debuginfo" && !data.HasDwarf()) {
+		f.Close()
+		return nil
+	}
+	return f
+}
+
+// debuginfod returns the path of the debuginfo or executable of a build ID in
+// the cache, downloading it if it's not there yet
+func debuginfod(id []byte, kind string) string {
+	if len(id) < 2 {
+		return ""
+	}
+	name := hex.EncodeToString(id)
+	path := filepath.Join(DebuginfodCache, name, kind)
+	if exists(path) {
+		return path
+	}
+	if DebuginfodOffline {
+		return ""
+	}
+
+	client := debuginfodClient()
+	for _, server := range DebuginfodURLs {
+		url := strings.TrimSuffix(server, "/") + "/buildid/" + name + "/" + kind
+		err := download(client, url, path)
+		if err == nil {
+			return path
+		}
+		if err != errNotFound {
+			log.Printf("Cannot download %s: %s\n", url, err)
+		}
+	}
+	return ""
+}
+
+// debuginfodClient returns a client that gives up on a server that doesn't
+// connect or answer in time (but not on a download that is still going)
+func debuginfodClient() *http.Client {
+	dialer := &net.Dialer{Timeout: DebuginfodTimeout}
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment, DialContext: dialer.DialContext,
+		TLSHandshakeTimeout: DebuginfodTimeout, ResponseHeaderTimeout: DebuginfodTimeout}
+	return &http.Client{Transport: transport}
+}
+
+// errNotFound is returned when a server doesn't have a file
+var errNotFound = fmt.Errorf("not found")
+
+// download saves a file from a server (to a temporary file first, so the
+// cache never has part of a file)
+func download(client *http.Client, url string, path string) error {
+	response, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s", response.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, response.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package file
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// A build ID, and the path a server has its debug information at
+var testBuildID = []byte{0xab, 0xcd, 0xef, 0x01}
+
+const testDebuginfoPath = "/buildid/abcdef01/debuginfo"
+
+// useDebuginfod points the debuginfod client at servers and an empty cache,
+// until the test is done
+func useDebuginfod(t *testing.T, urls ...string) {
+	urls0, cache0, timeout0, offline0 := DebuginfodURLs, DebuginfodCache, DebuginfodTimeout, DebuginfodOffline
+	t.Cleanup(func() {
+		DebuginfodURLs, DebuginfodCache, DebuginfodTimeout, DebuginfodOffline = urls0, cache0, timeout0, offline0
+	})
+	DebuginfodURLs = urls
+	DebuginfodCache = t.TempDir()
+	DebuginfodTimeout = 5 * time.Second
+	DebuginfodOffline = false
+}
+
+func TestDebuginfodHit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != testDebuginfoPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("debuginfo"))
+	}))
+	defer server.Close()
+	useDebuginfod(t, server.URL+"/")
+
+	path := debuginfod(testBuildID, "debuginfo")
+	if want := filepath.Join(DebuginfodCache, "abcdef01", "debuginfo"); path != want {
+		t.Fatalf("got path %q, want %q", path, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "debuginfo" {
+		t.Fatalf("got %q (%v) in the cache, want the file from the server", data, err)
+	}
+
+	// The second time, the file is in the cache
+	if again := debuginfod(testBuildID, "debuginfo"); again != path || requests != 1 {
+		t.Errorf("got %q after %d requests, want %q after 1", again, requests, path)
+	}
+}
+
+func TestDebuginfodNotFound(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	useDebuginfod(t, server.URL)
+
+	if path := debuginfod(testBuildID, "debuginfo"); path != "" {
+		t.Fatalf("got %q, want no file", path)
+	}
+	entries, _ := os.ReadDir(filepath.Join(DebuginfodCache, "abcdef01"))
+	if len(entries) != 0 {
+		t.Errorf("got %d files in the cache, want none", len(entries))
+	}
+}
+
+func TestDebuginfodTimeout(t *testing.T) {
+
+	// The slow server doesn't answer until the test is done, and the next has the file
+	done := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer slow.Close()
+	defer close(done)
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("debuginfo"))
+	}))
+	defer fast.Close()
+	useDebuginfod(t, slow.URL, fast.URL)
+	DebuginfodTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	path := debuginfod(testBuildID, "debuginfo")
+	if path == "" {
+		t.Fatal("got no file, want the file from the server that answered")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("took %s, want the slow server to time out", elapsed)
+	}
+}
+
+func TestDebuginfodSlowDownload(t *testing.T) {
+
+	// The server answers in time, and the file takes longer than the timeout
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("debug"))
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		w.Write([]byte("info"))
+	}))
+	defer server.Close()
+	useDebuginfod(t, server.URL)
+	DebuginfodTimeout = 100 * time.Millisecond
+
+	path := debuginfod(testBuildID, "debuginfo")
+	if data, err := os.ReadFile(path); err != nil || string(data) != "debuginfo" {
+		t.Errorf("got %q (%v), want the whole file", data, err)
+	}
+}
+
+func TestDebuginfodOffline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("got a request for %s offline", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	useDebuginfod(t, server.URL)
+	DebuginfodOffline = true
+
+	if path := debuginfod(testBuildID, "debuginfo"); path != "" {
+		t.Fatalf("got %q, want no file (it is not in the cache)", path)
+	}
+
+	// A file that is already in the cache is used
+	cached := filepath.Join(DebuginfodCache, "abcdef01", "debuginfo")
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cached, []byte("debuginfo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if path := debuginfod(testBuildID, "debuginfo"); path != cached {
+		t.Errorf("got %q, want %q from the cache", path, cached)
+	}
+}
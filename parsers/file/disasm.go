@@ -21,9 +21,14 @@ import (
 	"strings"
 	"text/tabwriter"
 
+	"github.com/vsoch/gosmeagle/pkg/debug/elf"
+
 	"golang.org/x/arch/arm/armasm"
 	"golang.org/x/arch/arm64/arm64asm"
+	"golang.org/x/arch/loong64/loong64asm"
 	"golang.org/x/arch/ppc64/ppc64asm"
+	"golang.org/x/arch/riscv64/riscv64asm"
+	"golang.org/x/arch/s390x/s390xasm"
 	"golang.org/x/arch/x86/x86asm"
 )
 
@@ -52,8 +57,9 @@ func (e *Entry) Disasm() (*Disasm, error) {
 
 	// We want to disassemble both known and unknown (imported) symbols
 	// TODO we could use this to derive import status?
+	// Static executables don't have a dynamic symbol table
 	dyns, err := e.DynamicSymbols()
-	if err != nil {
+	if err != nil && err != elf.ErrNoSymbols {
 		return nil, err
 	}
 
@@ -147,7 +153,9 @@ func NewFileCache(maxLen int) *FileCache {
 // and removes the least recently used file if necessary.
 // If the file is in cache, it is moved to the front of the list.
 func (fc *FileCache) Line(filename string, line int) ([]byte, error) {
-	if filepath.Ext(filename) != ".go" {
+	// Updated by @vsoch: the line tables in the debug information also have
+	// lines of C (and other) source files
+	if filename == "" {
 		return nil, nil
 	}
 
@@ -501,13 +509,72 @@ func disasm_ppc64(code []byte, pc uint64, lookup lookupFunc, byteOrder binary.By
 	return text, size
 }
 
+func disasm_riscv64(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder, gnuAsm bool) (string, int) {
+	inst, err := riscv64asm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else if gnuAsm {
+		text = fmt.Sprintf("%-36s // %s", riscv64asm.GoSyntax(inst, pc, lookup, textReader{code, pc}), riscv64asm.GNUSyntax(inst))
+	} else {
+		text = riscv64asm.GoSyntax(inst, pc, lookup, textReader{code, pc})
+	}
+	return text, size
+}
+
+func disasm_s390x(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder, gnuAsm bool) (string, int) {
+	inst, err := s390xasm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else if gnuAsm {
+		text = fmt.Sprintf("%-36s // %s", s390xasm.GoSyntax(inst, pc, lookup), s390xasm.GNUSyntax(inst, pc))
+	} else {
+		text = s390xasm.GoSyntax(inst, pc, lookup)
+	}
+	return text, size
+}
+
+func disasm_loong64(code []byte, pc uint64, lookup lookupFunc, _ binary.ByteOrder, gnuAsm bool) (string, int) {
+	inst, err := loong64asm.Decode(code)
+	var text string
+	if err != nil || inst.Op == 0 {
+		text = "?"
+	} else if gnuAsm {
+		text = fmt.Sprintf("%-36s // %s", loong64asm.GoSyntax(inst, pc, lookup), loong64asm.GNUSyntax(inst))
+	} else {
+		text = loong64asm.GoSyntax(inst, pc, lookup)
+	}
+	return text, 4
+}
+
+// There is no Go syntax for MIPS in x/arch, so we always show GNU syntax
+func disasm_mips(code []byte, pc uint64, _ lookupFunc, byteOrder binary.ByteOrder, _ bool) (string, int) {
+	return gnulookup_mips(code, pc, byteOrder)
+}
+
+func disasm_mips64(code []byte, pc uint64, _ lookupFunc, byteOrder binary.ByteOrder, _ bool) (string, int) {
+	return gnulookup_mips64(code, pc, byteOrder)
+}
+
 var disasms = map[string]disasmFunc{
-	"386":     disasm_386,
-	"amd64":   disasm_amd64,
-	"arm":     disasm_arm,
-	"arm64":   disasm_arm64,
-	"ppc64":   disasm_ppc64,
-	"ppc64le": disasm_ppc64,
+	"386":      disasm_386,
+	"amd64":    disasm_amd64,
+	"arm":      disasm_arm,
+	"arm64":    disasm_arm64,
+	"ppc64":    disasm_ppc64,
+	"ppc64le":  disasm_ppc64,
+	"riscv64":  disasm_riscv64,
+	"s390x":    disasm_s390x,
+	"loong64":  disasm_loong64,
+	"mips":     disasm_mips,
+	"mipsle":   disasm_mips,
+	"mips64":   disasm_mips64,
+	"mips64le": disasm_mips64,
 }
 
 // GNU assembly lookup
@@ -572,23 +639,89 @@ func gnulookup_ppc64(code []byte, pc uint64, byteOrder binary.ByteOrder) (string
 	return text, size
 }
 
+func gnulookup_riscv64(code []byte, pc uint64, _ binary.ByteOrder) (string, int) {
+	inst, err := riscv64asm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else {
+		text = riscv64asm.GNUSyntax(inst)
+	}
+	return text, size
+}
+
+func gnulookup_s390x(code []byte, pc uint64, _ binary.ByteOrder) (string, int) {
+	inst, err := s390xasm.Decode(code)
+	var text string
+	size := inst.Len
+	if err != nil || size == 0 || inst.Op == 0 {
+		size = 2
+		text = "?"
+	} else {
+		text = s390xasm.GNUSyntax(inst, pc)
+	}
+	return text, size
+}
+
+func gnulookup_loong64(code []byte, pc uint64, _ binary.ByteOrder) (string, int) {
+	inst, err := loong64asm.Decode(code)
+	var text string
+	if err != nil || inst.Op == 0 {
+		text = "?"
+	} else {
+		text = loong64asm.GNUSyntax(inst)
+	}
+	return text, 4
+}
+
+func gnulookup_mips(code []byte, pc uint64, byteOrder binary.ByteOrder) (string, int) {
+	text := decodeMIPS(code, pc, byteOrder, false)
+	if text == "" {
+		text = "?"
+	}
+	return text, 4
+}
+
+func gnulookup_mips64(code []byte, pc uint64, byteOrder binary.ByteOrder) (string, int) {
+	text := decodeMIPS(code, pc, byteOrder, true)
+	if text == "" {
+		text = "?"
+	}
+	return text, 4
+}
+
 var gnuLookup = map[string]gnuFunc{
-	"386":     gnulookup_386,
-	"amd64":   gnulookup_amd64,
-	"arm":     gnulookup_arm,
-	"arm64":   gnulookup_arm64,
-	"ppc64":   gnulookup_ppc64,
-	"ppc64le": gnulookup_ppc64,
+	"386":      gnulookup_386,
+	"amd64":    gnulookup_amd64,
+	"arm":      gnulookup_arm,
+	"arm64":    gnulookup_arm64,
+	"ppc64":    gnulookup_ppc64,
+	"ppc64le":  gnulookup_ppc64,
+	"riscv64":  gnulookup_riscv64,
+	"s390x":    gnulookup_s390x,
+	"loong64":  gnulookup_loong64,
+	"mips":     gnulookup_mips,
+	"mipsle":   gnulookup_mips,
+	"mips64":   gnulookup_mips64,
+	"mips64le": gnulookup_mips64,
 }
 
 var byteOrders = map[string]binary.ByteOrder{
-	"386":     binary.LittleEndian,
-	"amd64":   binary.LittleEndian,
-	"arm":     binary.LittleEndian,
-	"arm64":   binary.LittleEndian,
-	"ppc64":   binary.BigEndian,
-	"ppc64le": binary.LittleEndian,
-	"s390x":   binary.BigEndian,
+	"386":      binary.LittleEndian,
+	"amd64":    binary.LittleEndian,
+	"arm":      binary.LittleEndian,
+	"arm64":    binary.LittleEndian,
+	"ppc64":    binary.BigEndian,
+	"ppc64le":  binary.LittleEndian,
+	"s390x":    binary.BigEndian,
+	"riscv64":  binary.LittleEndian,
+	"loong64":  binary.LittleEndian,
+	"mips":     binary.BigEndian,
+	"mipsle":   binary.LittleEndian,
+	"mips64":   binary.BigEndian,
+	"mips64le": binary.LittleEndian,
 }
 
 type Liner interface {
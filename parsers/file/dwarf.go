@@ -3,8 +3,8 @@ package file
 import (
 	"fmt"
 	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
-	"io"
 	"reflect"
+	"strings"
 )
 
 // A common interface to represent a dwarf entry (what we need)
@@ -23,25 +23,58 @@ type FunctionEntry struct {
 	Params             []FormalParamEntry
 	Data               *dwarf.Data
 	FormalParamsLookup map[dwarf.Offset]*dwarf.Entry
+	CompileUnit        *dwarf.Entry // the unit the function was compiled in
+	Locals             []VariableEntry
+	Scopes             []Scope    // lexical blocks and inlined subroutines
+	CallSites          []CallSite // calls made from the function (at any depth)
+
+	// The declaration (DW_AT_specification) or abstract instance (DW_AT_abstract_origin)
+	// the entry completes, followed by what those refer to in turn
+	Origins []*dwarf.Entry
+
+	// The namespaces and types the function is declared in (outermost first)
+	Parents []*dwarf.Entry
+
+	// Template type and value parameters of a function template specialization
+	Templates []*dwarf.Entry
+}
+
+// A Scope is a lexical block or inlined subroutine in the body of a function
+type Scope struct {
+	Entry  *dwarf.Entry
+	Params []FormalParamEntry // an inlined subroutine has its own parameters
+	Locals []VariableEntry
+	Scopes []Scope
 }
 
 // Preparing a call site to link to a function / caller
 type CallSite struct {
 	Entry  dwarf.Entry
 	Params []dwarf.Entry
+	Data   *dwarf.Data
 }
 
 // Types that we need to parse
 type VariableEntry struct {
-	Entry *dwarf.Entry
-	Type  *dwarf.Type
-	Data  *dwarf.Data
+	Entry       *dwarf.Entry
+	Type        *dwarf.Type
+	Data        *dwarf.Data
+	CompileUnit *dwarf.Entry
 }
 
 type FormalParamEntry struct {
-	Entry *dwarf.Entry
-	Type  *dwarf.Type
-	Data  *dwarf.Data
+	Entry  *dwarf.Entry
+	Type   *dwarf.Type
+	Data   *dwarf.Data
+	Origin *dwarf.Entry // the abstract parameter of an inlined or cloned function
+}
+
+// Val returns an attribute of the parameter, falling back to its abstract origin
+func (p *FormalParamEntry) Val(attr dwarf.Attr) interface{} {
+	if value := p.Entry.Val(attr); value != nil || p.Origin == nil {
+		return value
+	}
+	return p.Origin.Val(attr)
 }
 
 // A Component can be a Field or param
@@ -51,7 +84,10 @@ type Component struct {
 	Size      int64
 	Type      string
 	Framebase string
-	RawType   interface{} // the original type
+	VarParam  bool            // Go marks result parameters with DW_AT_variable_parameter
+	Implicit  bool            // the compiler added it (e.g., the this pointer of a method)
+	Location  *dwarf.Location // where the compiler says it is (at function entry)
+	RawType   interface{}     // the original type
 }
 
 // GetUnderlyingType for a parameter or return value from AttrType
@@ -72,14 +108,64 @@ func (v *VariableEntry) GetType() *dwarf.Type   { return v.Type }
 func (f *FunctionEntry) GetEntry() *dwarf.Entry { return f.Entry }
 func (v *VariableEntry) GetEntry() *dwarf.Entry { return v.Entry }
 
+// Language returns the DW_LANG code of the function's compile unit (0 if unknown)
+func (f *FunctionEntry) Language() int64 {
+	if f.CompileUnit == nil {
+		return 0
+	}
+	lang, _ := f.CompileUnit.Val(dwarf.AttrLanguage).(int64)
+	return lang
+}
+
+// IsGo determines if a function was compiled by the Go toolchain
+func IsGo(entry DwarfEntry) bool {
+	function, ok := entry.(*FunctionEntry)
+	if !ok {
+		return false
+	}
+	if function.Language() == dwarf.LangGo {
+		return true
+	}
+	if function.CompileUnit != nil {
+		producer, _ := function.CompileUnit.Val(dwarf.AttrProducer).(string)
+		return strings.HasPrefix(producer, "Go cmd/compile")
+	}
+	return false
+}
+
+// Val returns an attribute of the function. An out of line definition or a
+// concrete instance often only has code addresses, and the name and type are
+// on the declaration or abstract instance it refers to.
+func (f *FunctionEntry) Val(attr dwarf.Attr) interface{} {
+	if value := f.Entry.Val(attr); value != nil {
+		return value
+	}
+	for _, origin := range f.Origins {
+		if value := origin.Val(attr); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// typeEntry returns the entry with the function's return type
+func (f *FunctionEntry) typeEntry() *dwarf.Entry {
+	for _, origin := range f.Origins {
+		if f.Entry.Val(dwarf.AttrType) == nil && origin.Val(dwarf.AttrType) != nil {
+			return origin
+		}
+	}
+	return f.Entry
+}
+
 // Get the name of the entry or formal param
 func (f *FunctionEntry) Name() string {
 
-	linkageName := f.Entry.Val(dwarf.AttrLinkageName)
+	linkageName := f.Val(dwarf.AttrLinkageName)
 	if linkageName != nil {
 		return linkageName.(string)
 	}
-	functionName := f.Entry.Val(dwarf.AttrName)
+	functionName := f.Val(dwarf.AttrName)
 	if functionName == nil {
 		return "anonymous"
 	}
@@ -100,10 +186,13 @@ func (v *VariableEntry) GetComponents() []Component {
 	if err != nil {
 		return comps
 	}
+	// A global variable has one location for the whole program
+	location, _ := v.Data.EvaluateLocation(v.Entry, dwarf.AttrLocation, 0)
+
 	// It looks like the Common().Name is empty here?
 	comps = append(comps, Component{Name: (varName).(string), Type: varType.String(),
 		RawType: varType.Common().Original, Class: GetStringType(varType),
-		Size: varType.Size()})
+		Size: varType.Size(), Location: location})
 	return comps
 }
 
@@ -129,6 +218,10 @@ func GetStringType(t dwarf.Type) string {
 		return "Qualified"
 	case *dwarf.PtrType:
 		return "Pointer"
+	case *dwarf.RefType:
+		return "Reference"
+	case *dwarf.PtrToMemberType:
+		return "MemberPointer"
 	case *dwarf.TypedefType:
 		return "Typedef"
 	case *dwarf.BasicType:
@@ -139,6 +232,8 @@ func GetStringType(t dwarf.Type) string {
 		return "Float"
 	case *dwarf.ArrayType:
 		return "Array"
+	case *dwarf.StringType:
+		return "String"
 	case *dwarf.UintType:
 		return "Uint"
 	case *dwarf.CharType:
@@ -157,13 +252,46 @@ func GetStringType(t dwarf.Type) string {
 	return "Unknown"
 }
 
+// TypedefChain follows a typedef (and any typedefs it refers to) to the
+// underlying type, returning the names of the typedefs, outermost first
+// (e.g., my_size, size_t for unsigned long)
+func TypedefChain(t *dwarf.TypedefType) ([]string, dwarf.Type) {
+	chain := []string{}
+	var underlying dwarf.Type = t
+	for {
+		typedef, ok := underlying.Common().Original.(*dwarf.TypedefType)
+		if !ok || len(chain) > maxTypedefDepth {
+			return chain, underlying
+		}
+		chain = append(chain, typedef.Name)
+		underlying = typedef.Type
+		if underlying == nil {
+			return chain, nil
+		}
+	}
+}
+
+// maxTypedefDepth stops a typedef chain that refers back to itself
+const maxTypedefDepth = 32
+
 // Function components are the associated fields
 func (f *FunctionEntry) GetComponents() []Component {
 
 	comps := []Component{}
+
+	// Parameter locations are evaluated where the function starts
+	lowpc, hasLowpc := f.Entry.Val(dwarf.AttrLowpc).(uint64)
+
 	for _, param := range f.Params {
 		entry := param.Entry
-		paramName := entry.Val(dwarf.AttrName)
+		if entry.Val(dwarf.AttrType) == nil && param.Origin != nil {
+			entry = param.Origin
+		}
+		paramName := param.Val(dwarf.AttrName)
+		implicit, _ := param.Val(dwarf.AttrArtificial).(bool)
+		if paramName == nil && implicit {
+			paramName = "this"
+		}
 
 		// If it's null, might just be a reference, check the lookup
 		if paramName == nil {
@@ -192,15 +320,21 @@ func (f *FunctionEntry) GetComponents() []Component {
 			continue
 		}
 
+		var location *dwarf.Location
+		if hasLowpc {
+			location, _ = f.Data.EvaluateLocation(param.Entry, dwarf.AttrLocation, lowpc)
+		}
+
+		varParam, _ := entry.Val(dwarf.AttrVarParam).(bool)
 		comps = append(comps, Component{Name: (paramName).(string), Type: paramType.Common().Name,
 			Class: GetStringType(paramType), Size: paramType.Common().ByteSize,
-			RawType: paramType.Common().Original})
+			RawType: paramType.Common().Original, VarParam: varParam, Implicit: implicit, Location: location})
 
 	}
 
 	// Get the Return value - for a library this is the only export (unless a call site)
-	returnType, err := GetUnderlyingType(f.Entry, f.Data)
-	if returnType != nil && err != nil {
+	returnType, err := GetUnderlyingType(f.typeEntry(), f.Data)
+	if returnType != nil && err == nil {
 		comps = append(comps, Component{Name: "return", Type: returnType.Common().Name,
 			Class: GetStringType(returnType), Size: returnType.Common().ByteSize,
 			RawType: returnType.Common().Original})
@@ -208,108 +342,350 @@ func (f *FunctionEntry) GetComponents() []Component {
 	return comps
 }
 
-// ParseDwarf and populate a lookup of Dwarf entries
+// ParseDwarf and populate a lookup of Dwarf entries. Functions and variables
+// are keyed by name ("functions", "variables") and by their compile unit and
+// offset ("offsets", see DieKey), since names are not unique.
 func ParseDwarf(dwf *dwarf.Data) map[string]map[string]DwarfEntry {
 
 	// We will return a lookup of Dwarf entry
 	lookup := map[string]map[string]DwarfEntry{}
 	lookup["functions"] = map[string]DwarfEntry{}
 	lookup["variables"] = map[string]DwarfEntry{}
+	lookup["offsets"] = map[string]DwarfEntry{}
 
-	// The reader will help us parse the DIEs
-	entryReader := dwf.Reader()
+	lookup["addresses"] = map[string]DwarfEntry{}
 
-	// keep track of last function to associate with formal parameters, and if found them
-	var functionEntry *dwarf.Entry
-	params := []FormalParamEntry{}
+	w := dwarfWalker{data: dwf, reader: dwf.Reader(), lookup: lookup,
+		subprograms: map[dwarf.Offset]dwarf.Entry{}, formalParams: map[dwarf.Offset]*dwarf.Entry{},
+		functions: map[dwarf.Offset]*FunctionEntry{}, params: map[dwarf.Offset]*dwarf.Entry{}}
+
+	// The top level entries are compile units, and everything else is a child
+	for entry, err := w.reader.Next(); entry != nil && err == nil; entry, err = w.reader.Next() {
+		switch entry.Tag {
+		case dwarf.TagCompileUnit, dwarf.TagPartialUnit:
+			w.unit = entry
+			w.eachChild(entry, w.visitDeclaration)
+		default:
+			w.skip(entry)
+		}
+	}
+
+	// Names and types can only be resolved once we have seen every entry
+	for _, function := range w.order {
+		w.resolveOrigins(function)
+		w.addFunction(function)
+	}
+
+	// Add param lookup to each function
+	for name, entry := range lookup["functions"] {
+		entry.(*FunctionEntry).FormalParamsLookup = w.formalParams
+		lookup["functions"][name] = entry
+	}
+
+	// Match call sites to subprograms
+	lookup["calls"] = ParseCallSites(dwf, &w.callSites, &w.subprograms, lookup["functions"])
+	return lookup
+}
+
+// A DieKey identifies a DIE by its compile unit and offset
+type DieKey struct {
+	Unit   dwarf.Offset
+	Offset dwarf.Offset
+}
+
+func (k DieKey) String() string { return fmt.Sprintf("%x:%x", k.Unit, k.Offset) }
+
+// Address returns the start of a function's code as a key for the "addresses" lookup
+func Address(pc uint64) string { return fmt.Sprintf("%x", pc) }
+
+// Key returns the unit and offset of a function
+func (f *FunctionEntry) Key() DieKey { return newDieKey(f.CompileUnit, f.Entry) }
+
+// Key returns the unit and offset of a variable
+func (v *VariableEntry) Key() DieKey { return newDieKey(v.CompileUnit, v.Entry) }
+
+func newDieKey(unit *dwarf.Entry, entry *dwarf.Entry) DieKey {
+	key := DieKey{Offset: entry.Offset}
+	if unit != nil {
+		key.Unit = unit.Offset
+	}
+	return key
+}
+
+// A dwarfWalker visits the DIE tree, attaching entries to their parent
+type dwarfWalker struct {
+	data   *dwarf.Data
+	reader *dwarf.Reader
+	lookup map[string]map[string]DwarfEntry
+	unit   *dwarf.Entry // the current compile unit
 
 	// Save a cache of call sites, params, and subprogram locations
-	var callSite *dwarf.Entry
-	var callSites []CallSite
-	var callSiteParams []dwarf.Entry
-	subprograms := map[dwarf.Offset]dwarf.Entry{}
+	callSites    []CallSite
+	subprograms  map[dwarf.Offset]dwarf.Entry
+	formalParams map[dwarf.Offset]*dwarf.Entry
 
-	// We need to keep a lookup of formal params for references
-	formalParams := map[dwarf.Offset]*dwarf.Entry{}
+	// Every function and parameter by offset, to resolve abstract origins
+	functions map[dwarf.Offset]*FunctionEntry
+	params    map[dwarf.Offset]*dwarf.Entry
+	order     []*FunctionEntry
 
-	for entry, err := entryReader.Next(); entry != nil; entry, err = entryReader.Next() {
+	// The namespaces and types we are in
+	parents []*dwarf.Entry
+}
 
-		// Reached end of file
-		if err == io.EOF {
-			break
+// eachChild calls visit for each child of entry. The reader must be positioned
+// just after entry, and visit must consume the children of what it is given.
+func (w *dwarfWalker) eachChild(entry *dwarf.Entry, visit func(*dwarf.Entry)) {
+	if !entry.Children {
+		return
+	}
+	for {
+		child, err := w.reader.Next()
+		if err != nil || child == nil || child.Tag == 0 {
+			return
 		}
+		visit(child)
+	}
+}
 
-		switch entry.Tag {
+// skip the children of an entry we don't need to look into
+func (w *dwarfWalker) skip(entry *dwarf.Entry) {
+	if entry.Children {
+		w.reader.SkipChildren()
+	}
+}
 
-		// DW_TAG_GNU_call_site is older version
-		case 0x4109, dwarf.TagCallSite, 0x44:
+// visitDeclaration handles entries at the unit level, or in a namespace or type
+func (w *dwarfWalker) visitDeclaration(entry *dwarf.Entry) {
+	switch entry.Tag {
 
-			// If we have a previous function entry, add it
-			if callSite != nil {
-				callSites = append(callSites, CallSite{Entry: (*callSite), Params: callSiteParams})
-			}
+	case dwarf.TagSubprogram:
+		w.visitFunction(entry)
+
+	// Variables here are globals (or static members)
+	case dwarf.TagVariable:
+		w.addVariable(&VariableEntry{Entry: entry, Data: w.data, CompileUnit: w.unit})
+		w.skip(entry)
+
+	// Classes can be the parents of methods
+	case dwarf.TagClassType:
+		w.subprograms[entry.Offset] = (*entry)
+		w.visitParent(entry)
 
-			// Reset params and set new function entry
-			callSite = entry
-			callSiteParams = []dwarf.Entry{}
+	case dwarf.TagNamespace, dwarf.TagStructType, dwarf.TagUnionType, dwarf.TagModule:
+		w.visitParent(entry)
 
-		// DW_TAG_GNU_call_site_parameter
-		case 0x410a, dwarf.TagCallSiteParameter, 0x45:
-			callSiteParams = append(callSiteParams, (*entry))
+	// Other types (including subroutine types and their parameters)
+	default:
+		w.skip(entry)
+	}
+}
 
-		// We found a function - hold onto it for any params
-		case dwarf.TagClassType:
-			subprograms[entry.Offset] = (*entry)
+// visitParent visits the declarations in a namespace or type
+func (w *dwarfWalker) visitParent(entry *dwarf.Entry) {
+	w.parents = append(w.parents, entry)
+	w.eachChild(entry, w.visitDeclaration)
+	w.parents = w.parents[:len(w.parents)-1]
+}
 
-		// We found a function - hold onto it for any params
-		case dwarf.TagSubprogram:
-			subprograms[entry.Offset] = (*entry)
+// visitFunction parses a subprogram and everything in its body
+func (w *dwarfWalker) visitFunction(entry *dwarf.Entry) {
+	w.subprograms[entry.Offset] = (*entry)
 
-			// If we have a previous function entry, add it
-			if functionEntry != nil {
-				newEntry := ParseFunction(dwf, functionEntry, params)
-				lookup["functions"][newEntry.Name()] = newEntry
+	function := &FunctionEntry{Entry: entry, Data: w.data, CompileUnit: w.unit, Params: []FormalParamEntry{},
+		Parents: append([]*dwarf.Entry{}, w.parents...)}
+	scope := Scope{Entry: entry}
+	w.eachChild(entry, func(child *dwarf.Entry) { w.visitScope(function, &scope, child) })
+
+	function.Params = scope.Params
+	function.Locals = scope.Locals
+	function.Scopes = scope.Scopes
+	w.functions[entry.Offset] = function
+	w.order = append(w.order, function)
+}
+
+// visitScope attaches an entry in a function body to its scope (the function
+// itself, a lexical block, or an inlined subroutine)
+func (w *dwarfWalker) visitScope(function *FunctionEntry, scope *Scope, entry *dwarf.Entry) {
+	switch entry.Tag {
+
+	case dwarf.TagFormalParameter:
+		w.params[entry.Offset] = entry
+
+		// Add named ones (not references) to the lookup
+		if entry.Val(dwarf.AttrName) != nil {
+			offset := entry.Val(dwarf.AttrType)
+			if offset != nil {
+				w.formalParams[offset.(dwarf.Offset)] = entry
 			}
+		}
+		scope.Params = append(scope.Params, ParseFormalParameter(w.data, entry))
+		w.skip(entry)
 
-			// Reset params and set new function entry
-			functionEntry = entry
-			params = []FormalParamEntry{}
+	case dwarf.TagVariable:
+		scope.Locals = append(scope.Locals, VariableEntry{Entry: entry, Data: w.data, CompileUnit: w.unit})
+		w.skip(entry)
 
-		// We match formal parameters to the last function (their parent)
-		case dwarf.TagFormalParameter:
+	case dwarf.TagTemplateTypeParameter, dwarf.TagTemplateValueParameter:
+		if scope.Entry == function.Entry {
+			function.Templates = append(function.Templates, entry)
+		}
+		w.skip(entry)
+
+	case dwarf.TagLexDwarfBlock, dwarf.TagInlinedSubroutine:
+		nested := Scope{Entry: entry}
+		w.eachChild(entry, func(child *dwarf.Entry) { w.visitScope(function, &nested, child) })
+		scope.Scopes = append(scope.Scopes, nested)
+
+	// DW_TAG_GNU_call_site is older version
+	case 0x4109, dwarf.TagCallSite, 0x44:
+		callSite := CallSite{Entry: (*entry), Params: []dwarf.Entry{}, Data: w.data}
+		w.eachChild(entry, func(child *dwarf.Entry) {
+			switch child.Tag {
+
+			// DW_TAG_GNU_call_site_parameter
+			case 0x410a, dwarf.TagCallSiteParameter, 0x45:
+				callSite.Params = append(callSite.Params, (*child))
+			}
+			w.skip(child)
+		})
+		w.callSites = append(w.callSites, callSite)
+		function.CallSites = append(function.CallSites, callSite)
 
-			// Add named ones (not references) to the lookup
-			paramName := entry.Val(dwarf.AttrName)
-			if paramName != nil {
-				offset := entry.Val(dwarf.AttrType)
-				if offset != nil {
-					formalParams[offset.(dwarf.Offset)] = entry
-				}
+	// Nested functions, and local classes that can have methods
+	case dwarf.TagSubprogram, dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType:
+		w.visitDeclaration(entry)
+
+	default:
+		w.skip(entry)
+	}
+}
+
+// maxOriginDepth bounds a chain of origins (a concrete instance refers to an
+// abstract instance, which refers to a declaration)
+const maxOriginDepth = 8
+
+// resolveOrigins follows DW_AT_specification and DW_AT_abstract_origin, and
+// matches the parameters of a concrete instance to the abstract ones
+func (w *dwarfWalker) resolveOrigins(function *FunctionEntry) {
+	var abstract *FunctionEntry
+	entry := function.Entry
+	for depth := 0; depth < maxOriginDepth; depth++ {
+		offset, ok := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+		if !ok {
+			offset, ok = entry.Val(dwarf.AttrSpecification).(dwarf.Offset)
+		}
+		if !ok {
+			break
+		}
+		origin, ok := w.functions[offset]
+		if !ok {
+			// The origin might be in a unit we did not visit, or not a function
+			reader := w.data.Reader()
+			reader.Seek(offset)
+			next, err := reader.Next()
+			if err != nil || next == nil {
+				break
 			}
-			params = append(params, ParseFormalParameter(dwf, entry))
+			origin = &FunctionEntry{Entry: next}
+		}
+		if abstract == nil && len(origin.Params) > 0 {
+			abstract = origin
+		}
 
-		// We've found a variable. We can make this more efficient by limiting to global
-		case dwarf.TagVariable:
-			newVariable := ParseVariable(dwf, entry)
-			lookup["variables"][newVariable.Name()] = newVariable
+		// An out of line definition is in the scope of its declaration
+		if len(function.Parents) == 0 {
+			function.Parents = origin.Parents
 		}
+		if len(function.Templates) == 0 {
+			function.Templates = origin.Templates
+		}
+		function.Origins = append(function.Origins, origin.Entry)
+		entry = origin.Entry
 	}
 
-	// Parse the last function entry
-	if functionEntry != nil {
-		newEntry := ParseFunction(dwf, functionEntry, params)
-		lookup["functions"][newEntry.Name()] = newEntry
+	for i, param := range function.Params {
+		if offset, ok := param.Entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset); ok {
+			function.Params[i].Origin = w.params[offset]
+		}
+	}
+	if abstract == nil {
+		return
 	}
 
-	// Add param lookup to each function
-	for name, entry := range lookup["functions"] {
-		entry.(*FunctionEntry).FormalParamsLookup = formalParams
-		lookup["functions"][name] = entry
+	// A concrete instance can leave out parameters that were optimized away,
+	// so we keep the order of the abstract instance and add what is missing
+	// (except for parameters the compiler added).
+	// An out of line definition has its own (named) parameters.
+	concrete := map[dwarf.Offset]FormalParamEntry{}
+	extra := []FormalParamEntry{}
+	for _, param := range function.Params {
+		if param.Origin != nil {
+			concrete[param.Origin.Offset] = param
+		} else {
+			extra = append(extra, param)
+		}
+	}
+	if len(function.Params) > 0 && len(concrete) == 0 {
+		return
 	}
+	params := []FormalParamEntry{}
+	for _, param := range abstract.Params {
+		if match, ok := concrete[param.Entry.Offset]; ok {
+			params = append(params, match)
 
-	// Match call sites to subprograms
-	lookup["calls"] = ParseCallSites(dwf, &callSites, &subprograms, lookup["functions"])
-	return lookup
+			// Parameters the compiler adds (e.g., __in_chrg) depend on the variant
+		} else if artificial, _ := param.Entry.Val(dwarf.AttrArtificial).(bool); !artificial {
+			params = append(params, param)
+		}
+	}
+	function.Params = append(params, extra...)
+}
+
+// CloneOrigin returns the name a compiler clone (e.g., foo.constprop.0 or
+// foo.isra.0) was made from. A clone can have a different ABI than its origin,
+// since constant or unused parameters are removed and others may be split up.
+// A .cold symbol isn't a clone: it is part of its function (with no entry of
+// its own).
+func CloneOrigin(name string) (string, bool) {
+	for _, suffix := range []string{".constprop.", ".isra.", ".part."} {
+		if index := strings.Index(name, suffix); index > 0 {
+			return name[:index], true
+		}
+	}
+	return name, false
+}
+
+// addFunction adds a function to the lookup. When there is more than one
+// function with a name, a definition is preferred over a declaration.
+func (w *dwarfWalker) addFunction(function *FunctionEntry) {
+	w.lookup["offsets"][function.Key().String()] = function
+	if lowpc, ok := function.Entry.Val(dwarf.AttrLowpc).(uint64); ok {
+		w.lookup["addresses"][Address(lowpc)] = function
+	}
+	name := function.Name()
+	if existing, ok := w.lookup["functions"][name]; ok && isDefinition(existing.GetEntry()) && !isDefinition(function.Entry) {
+		return
+	}
+	w.lookup["functions"][name] = function
+}
+
+// addVariable adds a global variable to the lookup, also preferring definitions
+func (w *dwarfWalker) addVariable(variable *VariableEntry) {
+	w.lookup["offsets"][variable.Key().String()] = variable
+	name := variable.Name()
+	if existing, ok := w.lookup["variables"][name]; ok && isDefinition(existing.GetEntry()) && !isDefinition(variable.Entry) {
+		return
+	}
+	w.lookup["variables"][name] = variable
+}
+
+// isDefinition determines if an entry has code or storage (and is not only a declaration)
+func isDefinition(entry *dwarf.Entry) bool {
+	if declaration, _ := entry.Val(dwarf.AttrDeclaration).(bool); declaration {
+		return false
+	}
+	return entry.Val(dwarf.AttrLowpc) != nil || entry.Val(dwarf.AttrRanges) != nil || entry.Val(dwarf.AttrLocation) != nil
 }
 
 // Parse Call sites into a map of DwarfEntry
@@ -0,0 +1,46 @@
+package file
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// compile builds a shared library with debug information from C source, or
+// skips the test if there is no compiler
+func compile(t *testing.T, source string) string {
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc is not installed")
+	}
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "lib.c")
+	if err := os.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	library := filepath.Join(dir, "lib.so")
+	if out, err := exec.Command("gcc", "-g", "-shared", "-fPIC", "-o", library, filename).CombinedOutput(); err != nil {
+		t.Fatalf("cannot compile: %v\n%s", err, out)
+	}
+	return library
+}
+
+// The return value of a function is its last component, and a function that
+// returns nothing has none
+func TestGetComponentsReturn(t *testing.T) {
+	f, err := Open(compile(t, "long add(int a, long b) { return a + b; }\nvoid nothing(int a) {}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	functions := f.ParseDwarf()["functions"]
+
+	comps := functions["add"].GetComponents()
+	if len(comps) != 3 || comps[2].Name != "return" || comps[2].Type != "long int" || comps[2].Size != 8 {
+		t.Errorf("got components %v of add, want a, b and a long int return value", comps)
+	}
+	comps = functions["nothing"].GetComponents()
+	if len(comps) != 1 || comps[0].Name != "a" {
+		t.Errorf("got components %v of nothing, want a", comps)
+	}
+}
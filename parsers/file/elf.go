@@ -13,6 +13,10 @@ import (
 
 type ElfFile struct {
 	elf *elf.File
+
+	// The symbols in .gnu_debugdata (MiniDebugInfo), read once
+	miniSyms []Symbol
+	miniRead bool
 }
 
 // Parse dwarf into the file object
@@ -29,7 +33,7 @@ func OpenElf(r io.ReaderAt) (rawFile, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &ElfFile{f}, nil
+	return &ElfFile{elf: f}, nil
 }
 
 // GetRelocations from te entire elf file - yes this is ugly and redundant, please improve!
@@ -81,7 +85,7 @@ func (f *ElfFile) GetRelocations() []Relocation {
 						symName = symbol.GetName()
 						symValue = symbol.GetAddress()
 					default:
-						fmt.Printf("Cannot find section name", f.elf.Sections[secIdx].Name)
+						fmt.Printf("Cannot find section name %s\n", f.elf.Sections[secIdx].Name)
 						continue
 					}
 					newReloc := Relocation{SymbolName: symName, Offset: uint64(offset), SymbolValue: symValue, RelocType: relocName, Info: info}
@@ -118,7 +122,7 @@ func (f *ElfFile) GetRelocations() []Relocation {
 						symName = symbol.GetName()
 						symValue = symbol.GetAddress()
 					default:
-						fmt.Printf("Cannot find section name", f.elf.Sections[secIdx].Name)
+						fmt.Printf("Cannot find section name %s\n", f.elf.Sections[secIdx].Name)
 						continue
 					}
 					newReloc := Relocation{SymbolName: symName, Offset: uint64(offset), SymbolValue: symValue, RelocType: relocName, Info: info}
@@ -159,7 +163,7 @@ func (f *ElfFile) GetRelocations() []Relocation {
 						symName = symbol.GetName()
 						symValue = symbol.GetAddress()
 					default:
-						fmt.Printf("Cannot find section name", f.elf.Sections[secIdx].Name)
+						fmt.Printf("Cannot find section name %s\n", f.elf.Sections[secIdx].Name)
 						continue
 					}
 					newReloc := Relocation{SymbolName: symName, Offset: uint64(offset), SymbolValue: symValue, RelocType: relocName, Info: info}
@@ -196,7 +200,7 @@ func (f *ElfFile) GetRelocations() []Relocation {
 						symName = symbol.GetName()
 						symValue = symbol.GetAddress()
 					default:
-						fmt.Printf("Cannot find section name", f.elf.Sections[secIdx].Name)
+						fmt.Printf("Cannot find section name %s\n", f.elf.Sections[secIdx].Name)
 						continue
 					}
 					newReloc := Relocation{SymbolName: symName, Offset: uint64(offset), SymbolValue: symValue, RelocType: relocName, Info: info}
@@ -205,7 +209,7 @@ func (f *ElfFile) GetRelocations() []Relocation {
 			}
 
 		default:
-			log.Fatalf("Unknown section type.", reflect.TypeOf(s))
+			log.Fatalf("Unknown section type %v", reflect.TypeOf(s))
 		}
 	}
 	return relocations
@@ -379,13 +383,19 @@ func (f *ElfFile) parseSymbols(elfSyms []elf.Symbol) ([]Symbol, error) {
 	return syms, nil
 }
 
-// Get all symbols for the elf file (but not imported)
+// Get all symbols for the elf file (but not imported), including those in
+// the MiniDebugInfo of a stripped binary
 func (f *ElfFile) Symbols() ([]Symbol, error) {
 	elfSyms, err := f.elf.Symbols()
-	if err != nil {
+	if err != nil && err != elf.ErrNoSymbols {
 		return nil, err
 	}
-	return f.parseSymbols(elfSyms)
+	syms, _ := f.parseSymbols(elfSyms)
+	mini := f.miniDebugSymbols(syms)
+	if err != nil && len(mini) == 0 {
+		return nil, err
+	}
+	return append(syms, mini...), nil
 }
 
 // getString extracts a string from an ELF string table.
@@ -450,6 +460,21 @@ func (f *ElfFile) GoArch() string {
 		return "ppc64"
 	case elf.EM_S390:
 		return "s390x"
+	case elf.EM_RISCV:
+		if f.elf.Class == elf.ELFCLASS64 {
+			return "riscv64"
+		}
+	case elf.EM_MIPS:
+		arch := "mips"
+		if f.elf.Class == elf.ELFCLASS64 {
+			arch = "mips64"
+		}
+		if f.elf.ByteOrder == binary.LittleEndian {
+			return arch + "le"
+		}
+		return arch
+	case elf.EM_LOONGARCH:
+		return "loong64"
 	}
 	return ""
 }
@@ -469,3 +494,29 @@ func (f *ElfFile) loadAddress() (uint64, error) {
 func (f *ElfFile) Dwarf() (*dwarf.Data, error) {
 	return f.elf.DWARF()
 }
+
+// HasDwarf determines if the file has debug information
+func (f *ElfFile) HasDwarf() bool {
+	return f.elf.HasDWARF()
+}
+
+// HasText determines if the file has code (a separate debug file does not)
+func (f *ElfFile) HasText() bool {
+	sect := f.elf.Section(".text")
+	return sect != nil && sect.Type != elf.SHT_NOBITS
+}
+
+// BuildID returns the build ID of the file, or nil
+func (f *ElfFile) BuildID() []byte {
+	id, err := f.elf.BuildID()
+	if err != nil {
+		return nil
+	}
+	return id
+}
+
+// DebugLink returns the name and CRC of the separate debug file, if there is one
+func (f *ElfFile) DebugLink() (string, uint32, bool) {
+	name, crc, err := f.elf.DebugLink()
+	return name, crc, err == nil
+}
@@ -0,0 +1,174 @@
+package file
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// Fortran passes arguments by reference, and a character argument has a
+// hidden length passed by value after all of the visible arguments (unless
+// the procedure is bind(C)). Arrays with a shape only known at run time are
+// passed as a pointer to a descriptor.
+
+// IsFortran determines if a function was compiled from Fortran
+func IsFortran(entry DwarfEntry) bool {
+	function, ok := entry.(*FunctionEntry)
+	if !ok {
+		return false
+	}
+	switch function.Language() {
+	case dwarf.LangFortran77, dwarf.LangFortran90, dwarf.LangFortran95, dwarf.LangFortran03, dwarf.LangFortran08:
+		return true
+	}
+	return false
+}
+
+// BindC guesses if a Fortran procedure is bind(C) from its symbol. Without
+// bind(C), compilers append an underscore (external procedures) or add the
+// module name (e.g., __mymod_MOD_solve for gfortran, mymod_mp_solve_ for ifort).
+func BindC(symbol string) bool {
+	return !strings.HasSuffix(symbol, "_") && !strings.Contains(symbol, "_MOD_") &&
+		!strings.Contains(symbol, "_mp_")
+}
+
+// characterType returns the character type of a Fortran argument, which is
+// usually behind a pointer or reference
+func characterType(t dwarf.Type) *dwarf.StringType {
+	for {
+		switch convert := t.(type) {
+		case *dwarf.StringType:
+			return convert
+		case *dwarf.PtrType:
+			t = convert.Type
+		case *dwarf.RefType:
+			t = convert.Type
+		case *dwarf.QualType:
+			t = convert.Type
+		case *dwarf.TypedefType:
+			t = convert.Type
+		default:
+			return nil
+		}
+	}
+}
+
+// HiddenLengths adds the hidden length of each character argument of a Fortran
+// procedure, after the visible arguments (and before the return value). Some
+// compilers (e.g., gfortran) describe them as artificial parameters already.
+func HiddenLengths(entry DwarfEntry, symbol string, comps []Component) []Component {
+	if !IsFortran(entry) || BindC(symbol) {
+		return comps
+	}
+
+	params, result := comps, []Component{}
+	if len(comps) > 0 && comps[len(comps)-1].Name == "return" {
+		params, result = comps[:len(comps)-1], comps[len(comps)-1:]
+	}
+
+	existing := map[string]bool{}
+	for _, c := range params {
+		if c.Implicit {
+			existing[c.Name] = true
+		}
+	}
+
+	lengths := []Component{}
+	for _, c := range params {
+		t, ok := c.RawType.(dwarf.Type)
+		if !ok || c.Implicit || characterType(t) == nil || existing["_"+c.Name] {
+			continue
+		}
+		length := &dwarf.IntType{}
+		length.Name = "integer(kind=8)"
+		length.ByteSize = 8
+		length.Original = length
+		lengths = append(lengths, Component{Name: "_" + c.Name, Class: "Int", Size: 8, Type: length.Name,
+			Implicit: true, RawType: length})
+	}
+	if len(lengths) == 0 {
+		return comps
+	}
+	return append(append(append([]Component{}, params...), lengths...), result...)
+}
+
+// DescribeBound describes an array bound for a descriptor
+func DescribeBound(bound dwarf.Bound) string {
+	switch {
+	case bound.IsConstant:
+		return fmt.Sprintf("%d", bound.Constant)
+	case bound.Expr != nil:
+		if offset, ok := dwarf.DescriptorOffset(bound.Expr); ok {
+			return fmt.Sprintf("descriptor+%d", offset)
+		}
+		return "runtime"
+	case bound.Variable != 0:
+		return "variable"
+	}
+	return ""
+}
+
+// descriptorField is the name and size of a field of an array descriptor
+type descriptorField struct {
+	name string
+	size int64
+}
+
+// The fields of a C descriptor (CFI_cdesc_t) and one of its dimensions (CFI_dim_t)
+var cfiFields = []descriptorField{{"base_addr", 8}, {"elem_len", 8}, {"version", 4}, {"rank", 1},
+	{"attribute", 1}, {"type", 2}}
+var cfiDimFields = []descriptorField{{"lower_bound", 8}, {"extent", 8}, {"sm", 8}}
+
+// The fields of a gfortran (8 and later) descriptor and one of its dimensions
+var gfortranFields = []descriptorField{{"base_addr", 8}, {"offset", 8}, {"dtype.elem_len", 8},
+	{"dtype.version", 4}, {"dtype.rank", 1}, {"dtype.type", 1}, {"dtype.attribute", 2}, {"span", 8}}
+var gfortranDimFields = []descriptorField{{"stride", 8}, {"lbound", 8}, {"ubound", 8}}
+
+// ArrayDescriptor returns the layout of the descriptor passed for an array with
+// a shape known at run time, or nil if the array is not described by one. The
+// kind is recognized from where the lower bound of the first dimension is read.
+func ArrayDescriptor(t *dwarf.ArrayType) *descriptor.ArrayDescriptor {
+	if !t.Dynamic() {
+		return nil
+	}
+
+	// For an assumed rank array, the kind is recognized from where the rank is
+	expr, cfiOffset, gfortranOffset := t.Rank.Expr, int64(20), int64(28)
+	if len(t.Bounds) > 0 {
+		expr, cfiOffset, gfortranOffset = t.Bounds[0].Lower.Expr, 24, 48
+	}
+	fields, dimFields, kind := cfiFields, cfiDimFields, "CFI"
+	offset, ok := dwarf.DescriptorOffset(expr)
+	switch {
+	case ok && offset == gfortranOffset:
+		fields, dimFields, kind = gfortranFields, gfortranDimFields, "gfortran"
+	case ok && offset == cfiOffset:
+	default:
+		return nil
+	}
+
+	layout := descriptor.ArrayDescriptor{Kind: kind, Rank: int64(len(t.Bounds))}
+	offset = 0
+	for _, field := range fields {
+		layout.Fields = append(layout.Fields, descriptor.DescriptorField{Name: field.name, Offset: offset, Size: field.size})
+		offset += field.size
+	}
+
+	// The number of dimensions of an assumed rank array is only known at run time
+	if t.Rank.Known() {
+		layout.Rank = -1
+		layout.Fields = append(layout.Fields, descriptor.DescriptorField{Name: "dim", Offset: offset})
+		return &layout
+	}
+	for i := range t.Bounds {
+		for _, field := range dimFields {
+			name := fmt.Sprintf("dim[%d].%s", i, field.name)
+			layout.Fields = append(layout.Fields, descriptor.DescriptorField{Name: name, Offset: offset, Size: field.size})
+			offset += field.size
+		}
+	}
+	layout.Size = offset
+	return &layout
+}
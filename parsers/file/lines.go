@@ -0,0 +1,119 @@
+package file
+
+import (
+	"debug/gosym"
+	"sort"
+
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// The line tables in the debug information (.debug_line) map each pc of C,
+// C++ (and other) compile units to a file, line and column. They can only be
+// read forward, so the rows of every table are read once and sorted by pc.
+
+// DwarfLiner is a Liner for the line tables in the debug information
+type DwarfLiner struct {
+	rows []lineRow
+}
+
+// A lineRow applies from its address to the address of the next row, unless
+// it ends a sequence (and then the next addresses have no line)
+type lineRow struct {
+	address uint64
+	file    string
+	line    int
+	column  int
+	end     bool
+}
+
+// NewDwarfLiner reads the line tables of each compile unit, and returns nil if
+// there are none
+func NewDwarfLiner(d *dwarf.Data) (*DwarfLiner, error) {
+	liner := &DwarfLiner{}
+	seen := map[int64]bool{}
+
+	reader := d.Reader()
+	for entry, err := reader.Next(); entry != nil; entry, err = reader.Next() {
+		if err != nil {
+			return nil, err
+		}
+		reader.SkipChildren()
+		switch entry.Tag {
+		case dwarf.TagCompileUnit, dwarf.TagPartialUnit, dwarf.TagSkeletonUnit:
+		default:
+			continue
+		}
+
+		// Units can share a line table (e.g., a partial unit)
+		offset, ok := entry.Val(dwarf.AttrStmtList).(int64)
+		if !ok || seen[offset] {
+			continue
+		}
+		seen[offset] = true
+
+		lines, err := d.LineReader(entry)
+		if err != nil {
+			return nil, err
+		}
+		if lines == nil {
+			continue
+		}
+		var line dwarf.LineEntry
+		start := len(liner.rows)
+		for lines.Next(&line) == nil {
+			row := lineRow{address: line.Address, line: line.Line, column: line.Column, end: line.EndSequence}
+			if line.File != nil {
+				row.file = line.File.Name
+			}
+
+			// A row followed by another at the same address covers nothing
+			if last := len(liner.rows) - 1; last >= start && liner.rows[last].address == row.address {
+				liner.rows[last] = row
+			} else {
+				liner.rows = append(liner.rows, row)
+			}
+			if row.end {
+				start = len(liner.rows)
+			}
+		}
+	}
+	if len(liner.rows) == 0 {
+		return nil, nil
+	}
+
+	// A sequence can start where another ends, so the end goes first
+	sort.SliceStable(liner.rows, func(i, j int) bool {
+		if liner.rows[i].address != liner.rows[j].address {
+			return liner.rows[i].address < liner.rows[j].address
+		}
+		return liner.rows[i].end && !liner.rows[j].end
+	})
+	return liner, nil
+}
+
+// find returns the row that a pc is in, or nil
+func (l *DwarfLiner) find(pc uint64) *lineRow {
+	i := sort.Search(len(l.rows), func(i int) bool { return l.rows[i].address > pc })
+	if i == 0 || l.rows[i-1].end {
+		return nil
+	}
+	return &l.rows[i-1]
+}
+
+// PCToLine returns the file and line of a pc (there is no Go function data)
+func (l *DwarfLiner) PCToLine(pc uint64) (string, int, *gosym.Func) {
+	row := l.find(pc)
+	if row == nil {
+		return "", 0, nil
+	}
+	return row.file, row.line, nil
+}
+
+// PCToLineColumn returns the file, line and column of a pc
+func (l *DwarfLiner) PCToLineColumn(pc uint64) (string, int, int) {
+	row := l.find(pc)
+	if row == nil {
+		return "", 0, 0
+	}
+	return row.file, row.line, row.column
+}
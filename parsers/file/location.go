@@ -0,0 +1,45 @@
+package file
+
+import (
+	"fmt"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// DescribeLocation converts a DWARF location for the corpus, naming registers
+// with an architecture's DWARF register map (unknown ones are "reg<N>")
+func DescribeLocation(loc *dwarf.Location, registers map[int]string) *descriptor.DwarfLocation {
+	if loc == nil || len(loc.Pieces) == 0 {
+		return nil
+	}
+	name := func(number int) string {
+		if register, ok := registers[number]; ok {
+			return register
+		}
+		return fmt.Sprintf("reg%d", number)
+	}
+
+	described := descriptor.DwarfLocation{}
+	for _, piece := range loc.Pieces {
+		newPiece := descriptor.DwarfPiece{Kind: piece.Kind.String(), Size: piece.Size, BitSize: piece.BitSize,
+			BitOffset: piece.BitOffset}
+
+		switch piece.Kind {
+		case dwarf.PieceRegister:
+			newPiece.Register = name(piece.Register)
+		case dwarf.PieceMemory, dwarf.PieceValue:
+			newPiece.Base = piece.Base.String()
+			newPiece.Offset = piece.Offset
+			if piece.Base == dwarf.BaseRegister || piece.Base == dwarf.BaseEntryValue {
+				newPiece.Register = name(piece.Register)
+			}
+		case dwarf.PieceImplicitValue:
+			newPiece.Value = fmt.Sprintf("%x", piece.Value)
+		case dwarf.PieceImplicitPointer:
+			newPiece.Offset = piece.Offset
+		}
+		described.Pieces = append(described.Pieces, newPiece)
+	}
+	return &described
+}
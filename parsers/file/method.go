@@ -0,0 +1,179 @@
+package file
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// A Method describes a C++ member function
+type Method struct {
+	Class        string // the qualified name of the enclosing class
+	Static       bool
+	Virtual      bool
+	Const        bool
+	RefQualifier string // "&" or "&&" for ref-qualified methods
+}
+
+// scopeName returns the name of a namespace or type for a qualified name
+func scopeName(entry *dwarf.Entry) string {
+	if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+		return name
+	}
+	if entry.Tag == dwarf.TagNamespace {
+		return "(anonymous namespace)"
+	}
+	return "(anonymous)"
+}
+
+// qualify joins the names of parents (outermost first) and a name with ::
+func qualify(parents []*dwarf.Entry, name string) string {
+	names := []string{}
+	for _, parent := range parents {
+		names = append(names, scopeName(parent))
+	}
+	return strings.Join(append(names, name), "::")
+}
+
+// QualifiedName returns the source name of a function with its namespaces
+// and classes (e.g., geo::Shape::area), or an empty string if there are none
+func (f *FunctionEntry) QualifiedName() string {
+	name, ok := f.Val(dwarf.AttrName).(string)
+	if !ok || len(f.Parents) == 0 {
+		return ""
+	}
+	return qualify(f.Parents, name)
+}
+
+// Method returns how a member function is declared, or nil if the function
+// is not in a class (or struct or union)
+func (f *FunctionEntry) Method() *Method {
+	if len(f.Parents) == 0 {
+		return nil
+	}
+	parent := f.Parents[len(f.Parents)-1]
+	switch parent.Tag {
+	case dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType:
+	default:
+		return nil
+	}
+
+	method := Method{Class: qualify(f.Parents[:len(f.Parents)-1], scopeName(parent))}
+	virtuality, _ := f.Val(dwarf.AttrVirtuality).(int64)
+	method.Virtual = virtuality != 0
+	if reference, _ := f.Val(dwarf.AttrReference).(bool); reference {
+		method.RefQualifier = "&"
+	}
+	if reference, _ := f.Val(dwarf.AttrRvalueReference).(bool); reference {
+		method.RefQualifier = "&&"
+	}
+
+	// A static method has no this pointer, and in a const method it points to const
+	this := f.thisParam()
+	if this == nil {
+		method.Static = f.Val(dwarf.AttrObjectPointer) == nil
+		return &method
+	}
+	entry := this.Entry
+	if entry.Val(dwarf.AttrType) == nil && this.Origin != nil {
+		entry = this.Origin
+	}
+	if thisType, err := GetUnderlyingType(entry, f.Data); err == nil && thisType != nil {
+		if pointer, ok := unqualified(thisType).(*dwarf.PtrType); ok {
+			if qualified, ok := pointer.Type.(*dwarf.QualType); ok && qualified.Qual == "const" {
+				method.Const = true
+			}
+		}
+	}
+	return &method
+}
+
+// thisParam returns the implicit (artificial) this parameter of a method
+func (f *FunctionEntry) thisParam() *FormalParamEntry {
+	for i := range f.Params {
+		if artificial, _ := f.Params[i].Val(dwarf.AttrArtificial).(bool); artificial {
+			return &f.Params[i]
+		}
+	}
+	return nil
+}
+
+// unqualified removes const and volatile from a type (e.g., Shape *const this)
+func unqualified(t dwarf.Type) dwarf.Type {
+	for {
+		qualified, ok := t.(*dwarf.QualType)
+		if !ok {
+			return t
+		}
+		t = qualified.Type
+	}
+}
+
+// Unqualified removes const and volatile from the type of a component
+func (c Component) Unqualified() Component {
+	t, ok := c.RawType.(dwarf.Type)
+	if !ok {
+		return c
+	}
+	t = unqualified(t)
+	c.RawType = t.Common().Original
+	c.Class = GetStringType(t)
+	c.Type = t.String()
+	return c
+}
+
+// TemplateParams returns the template arguments of a function template specialization
+func (f *FunctionEntry) TemplateParams() []*dwarf.TemplateParam {
+	params := []*dwarf.TemplateParam{}
+	for _, entry := range f.Templates {
+		if param, err := f.Data.TemplateParam(entry); err == nil {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// DescribeTemplateParams converts template arguments for a descriptor
+func DescribeTemplateParams(params []*dwarf.TemplateParam) []descriptor.TemplateArgument {
+	args := []descriptor.TemplateArgument{}
+	for _, param := range params {
+		arg := descriptor.TemplateArgument{Name: param.Name, Kind: "type", Type: param.Type.String()}
+		if param.IsValue {
+			arg.Kind = "value"
+			switch value := param.Value.(type) {
+			case int64:
+				arg.Value = strconv.FormatInt(value, 10)
+			case uint64:
+				arg.Value = strconv.FormatUint(value, 10)
+			case []byte:
+				arg.Value = hex.EncodeToString(value)
+			}
+		}
+		args = append(args, arg)
+	}
+	return args
+}
+
+// TemplateName removes the template arguments from the name of a
+// specialization (e.g., Box<int, 4> is Box). Other names are unchanged.
+func TemplateName(name string) string {
+	if !strings.HasSuffix(name, ">") || strings.HasPrefix(name, "operator") {
+		return name
+	}
+	depth := 0
+	for i := len(name) - 1; i >= 0; i-- {
+		switch name[i] {
+		case '>':
+			depth++
+		case '<':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(name[:i])
+			}
+		}
+	}
+	return name
+}
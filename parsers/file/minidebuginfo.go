@@ -0,0 +1,89 @@
+package file
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/ulikunitz/xz"
+	"github.com/vsoch/gosmeagle/pkg/debug/elf"
+)
+
+// Some distributions (e.g., Fedora) strip binaries, but keep an xz compressed
+// ELF file in .gnu_debugdata (MiniDebugInfo) with a symbol table of the
+// functions that are not in the dynamic symbol table (e.g., static functions).
+
+// maxMiniDebugInfo limits the size of the decompressed file
+const maxMiniDebugInfo = 256 << 20
+
+// miniDebugInfo decompresses the file in .gnu_debugdata, or returns nil if
+// there isn't one
+func (f *ElfFile) miniDebugInfo() (*elf.File, error) {
+	section := f.elf.Section(".gnu_debugdata")
+	if section == nil {
+		return nil, nil
+	}
+	reader, err := xz.NewReader(section.Open())
+	if err != nil {
+		return nil, err
+	}
+	data, err := readLimited(reader, maxMiniDebugInfo)
+	if err != nil {
+		return nil, err
+	}
+	return elf.NewFile(bytes.NewReader(data))
+}
+
+// readLimited reads all of a reader, or fails if there is more than limit
+func readLimited(reader io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// miniDebugSymbols returns the symbols in .gnu_debugdata that are not in syms.
+// They are decompressed and parsed the first time, and kept for the next.
+func (f *ElfFile) miniDebugSymbols(syms []Symbol) []Symbol {
+	if !f.miniRead {
+		f.miniRead = true
+		f.miniSyms = f.readMiniDebugSymbols()
+	}
+
+	seen := map[string]bool{}
+	for _, symbol := range syms {
+		seen[symbol.GetName()] = true
+	}
+	added := []Symbol{}
+	for _, symbol := range f.miniSyms {
+		if !seen[symbol.GetName()] {
+			added = append(added, symbol)
+		}
+	}
+	return added
+}
+
+// readMiniDebugSymbols reads the symbols of the file in .gnu_debugdata
+func (f *ElfFile) readMiniDebugSymbols() []Symbol {
+	mini, err := f.miniDebugInfo()
+	if err != nil {
+		log.Printf("Cannot read the MiniDebugInfo (.gnu_debugdata): %s", err)
+		return nil
+	}
+	if mini == nil {
+		return nil
+	}
+	elfSyms, err := mini.Symbols()
+	if err != nil {
+		return nil
+	}
+
+	// The section headers are kept, so the symbols are parsed with them
+	miniSyms, _ := (&ElfFile{elf: mini}).parseSymbols(elfSyms)
+	return miniSyms
+}
@@ -0,0 +1,15 @@
+package file
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadLimited(t *testing.T) {
+	if data, err := readLimited(strings.NewReader("debug"), 5); err != nil || string(data) != "debug" {
+		t.Errorf("got %q (%v), want all of it", data, err)
+	}
+	if data, err := readLimited(strings.NewReader("debuginfo"), 5); err == nil {
+		t.Errorf("got %q, want an error for more than the limit", data)
+	}
+}
@@ -0,0 +1,296 @@
+package file
+
+// golang.org/x/arch does not (yet) have a MIPS decoder, so this is a small
+// one for the MIPS32/MIPS64 (release 2) integer and FPU instructions that
+// compilers commonly emit. Output follows GNU objdump syntax.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// o32 register names, n64 renames $8-$11 to a4-a7 and $12-$15 to t0-t3
+var mipsRegisters = []string{
+	"zero", "at", "v0", "v1", "a0", "a1", "a2", "a3",
+	"t0", "t1", "t2", "t3", "t4", "t5", "t6", "t7",
+	"s0", "s1", "s2", "s3", "s4", "s5", "s6", "s7",
+	"t8", "t9", "k0", "k1", "gp", "sp", "s8", "ra",
+}
+
+var mips64Registers = []string{
+	"zero", "at", "v0", "v1", "a0", "a1", "a2", "a3",
+	"a4", "a5", "a6", "a7", "t0", "t1", "t2", "t3",
+	"s0", "s1", "s2", "s3", "s4", "s5", "s6", "s7",
+	"t8", "t9", "k0", "k1", "gp", "sp", "s8", "ra",
+}
+
+// SPECIAL (opcode 0) three register and shift instructions, by function
+var mipsSpecial = map[uint32]string{
+	0x04: "sllv", 0x06: "srlv", 0x07: "srav", 0x14: "dsllv", 0x16: "dsrlv", 0x17: "dsrav",
+	0x0a: "movz", 0x0b: "movn", 0x20: "add", 0x21: "addu", 0x22: "sub", 0x23: "subu",
+	0x24: "and", 0x25: "or", 0x26: "xor", 0x27: "nor", 0x2a: "slt", 0x2b: "sltu",
+	0x2c: "dadd", 0x2d: "daddu", 0x2e: "dsub", 0x2f: "dsubu",
+}
+
+var mipsShifts = map[uint32]string{
+	0x00: "sll", 0x02: "srl", 0x03: "sra", 0x38: "dsll", 0x3a: "dsrl", 0x3b: "dsra",
+	0x3c: "dsll32", 0x3e: "dsrl32", 0x3f: "dsra32",
+}
+
+var mipsMultDiv = map[uint32]string{
+	0x18: "mult", 0x19: "multu", 0x1a: "div", 0x1b: "divu",
+	0x1c: "dmult", 0x1d: "dmultu", 0x1e: "ddiv", 0x1f: "ddivu",
+	0x30: "tge", 0x31: "tgeu", 0x32: "tlt", 0x33: "tltu", 0x34: "teq", 0x36: "tne",
+}
+
+// Immediate arithmetic (rt, rs, imm), by opcode
+var mipsImmediate = map[uint32]string{
+	0x08: "addi", 0x09: "addiu", 0x0a: "slti", 0x0b: "sltiu", 0x0c: "andi", 0x0d: "ori",
+	0x0e: "xori", 0x18: "daddi", 0x19: "daddiu",
+}
+
+// Loads and stores (rt, offset(base)), by opcode
+var mipsMemory = map[uint32]string{
+	0x1a: "ldl", 0x1b: "ldr", 0x20: "lb", 0x21: "lh", 0x22: "lwl", 0x23: "lw", 0x24: "lbu",
+	0x25: "lhu", 0x26: "lwr", 0x27: "lwu", 0x28: "sb", 0x29: "sh", 0x2a: "swl", 0x2b: "sw",
+	0x2c: "sdl", 0x2d: "sdr", 0x2e: "swr", 0x30: "ll", 0x34: "lld", 0x37: "ld", 0x38: "sc",
+	0x3c: "scd", 0x3f: "sd",
+}
+
+// FPU loads and stores (ft, offset(base)), by opcode
+var mipsFPUMemory = map[uint32]string{0x31: "lwc1", 0x35: "ldc1", 0x39: "swc1", 0x3d: "sdc1"}
+
+// FPU arithmetic by function, and formats by the fmt field
+var mipsFPU = map[uint32]string{
+	0x00: "add", 0x01: "sub", 0x02: "mul", 0x03: "div", 0x04: "sqrt", 0x05: "abs", 0x06: "mov", 0x07: "neg",
+	0x09: "trunc.l", 0x0d: "trunc.w", 0x20: "cvt.s", 0x21: "cvt.d", 0x24: "cvt.w", 0x25: "cvt.l",
+}
+
+var mipsFPUFormats = map[uint32]string{0x10: "s", 0x11: "d", 0x14: "w", 0x15: "l"}
+
+var mipsConditions = []string{"f", "un", "eq", "ueq", "olt", "ult", "ole", "ule",
+	"sf", "ngle", "seq", "ngl", "lt", "nge", "le", "ngt"}
+
+// decodeMIPS decodes one 4 byte instruction, returning an empty string if unknown
+func decodeMIPS(code []byte, pc uint64, ord binary.ByteOrder, is64 bool) string {
+	if len(code) < 4 {
+		return ""
+	}
+	inst := ord.Uint32(code)
+
+	regs := mipsRegisters
+	if is64 {
+		regs = mips64Registers
+	}
+
+	opcode := inst >> 26
+	rs := regs[(inst>>21)&0x1f]
+	rt := regs[(inst>>16)&0x1f]
+	rd := regs[(inst>>11)&0x1f]
+	sa := (inst >> 6) & 0x1f
+	funct := inst & 0x3f
+	imm := int64(int16(inst & 0xffff))
+	uimm := inst & 0xffff
+
+	// Branch targets are relative to the delay slot
+	branch := fmt.Sprintf("0x%x", uint64(int64(pc)+4+imm*4))
+
+	join := func(op string, args ...string) string {
+		if len(args) == 0 {
+			return op
+		}
+		return op + "\t" + strings.Join(args, ",")
+	}
+
+	switch opcode {
+
+	// SPECIAL
+	case 0x00:
+		if inst == 0 {
+			return "nop"
+		}
+		if op, ok := mipsShifts[funct]; ok && (inst>>21)&0x1f == 0 {
+			return join(op, rd, rt, fmt.Sprintf("0x%x", sa))
+		}
+		if op, ok := mipsSpecial[funct]; ok {
+			switch {
+			case strings.HasPrefix(op, "s") && strings.HasSuffix(op, "v"), strings.HasPrefix(op, "ds") && strings.HasSuffix(op, "v"):
+				return join(op, rd, rt, rs)
+			case (op == "or" || op == "addu" || op == "daddu") && (inst>>16)&0x1f == 0:
+				return join("move", rd, rs)
+			case (op == "or" || op == "addu" || op == "daddu") && (inst>>21)&0x1f == 0:
+				return join("move", rd, rt)
+			}
+			return join(op, rd, rs, rt)
+		}
+		if op, ok := mipsMultDiv[funct]; ok {
+			return join(op, rs, rt)
+		}
+		switch funct {
+		case 0x08:
+			return join("jr", rs)
+		case 0x09:
+			if (inst>>11)&0x1f == 31 {
+				return join("jalr", rs)
+			}
+			return join("jalr", rd, rs)
+		case 0x0c:
+			return "syscall"
+		case 0x0d:
+			return "break"
+		case 0x0f:
+			return "sync"
+		case 0x10:
+			return join("mfhi", rd)
+		case 0x11:
+			return join("mthi", rs)
+		case 0x12:
+			return join("mflo", rd)
+		case 0x13:
+			return join("mtlo", rs)
+		}
+
+	// REGIMM
+	case 0x01:
+		switch (inst >> 16) & 0x1f {
+		case 0x00:
+			return join("bltz", rs, branch)
+		case 0x01:
+			return join("bgez", rs, branch)
+		case 0x10:
+			return join("bltzal", rs, branch)
+		case 0x11:
+			if (inst>>21)&0x1f == 0 {
+				return join("bal", branch)
+			}
+			return join("bgezal", rs, branch)
+		}
+
+	// Jumps stay within the current 256MB region
+	case 0x02, 0x03:
+		target := ((pc + 4) &^ 0x0fffffff) | uint64(inst&0x03ffffff)<<2
+		op := "j"
+		if opcode == 0x03 {
+			op = "jal"
+		}
+		return join(op, fmt.Sprintf("0x%x", target))
+
+	case 0x04:
+		if (inst>>16)&0x1f == 0 {
+			if (inst>>21)&0x1f == 0 {
+				return join("b", branch)
+			}
+			return join("beqz", rs, branch)
+		}
+		return join("beq", rs, rt, branch)
+	case 0x05:
+		if (inst>>16)&0x1f == 0 {
+			return join("bnez", rs, branch)
+		}
+		return join("bne", rs, rt, branch)
+	case 0x06:
+		return join("blez", rs, branch)
+	case 0x07:
+		return join("bgtz", rs, branch)
+
+	case 0x0f:
+		return join("lui", rt, fmt.Sprintf("0x%x", uimm))
+
+	// SPECIAL2
+	case 0x1c:
+		switch funct {
+		case 0x02:
+			return join("mul", rd, rs, rt)
+		case 0x20:
+			return join("clz", rd, rs)
+		case 0x21:
+			return join("clo", rd, rs)
+		}
+
+	// SPECIAL3
+	case 0x1f:
+		switch funct {
+		case 0x00:
+			return join("ext", rt, rs, fmt.Sprintf("0x%x", sa), fmt.Sprintf("0x%x", ((inst>>11)&0x1f)+1))
+		case 0x03:
+			return join("dext", rt, rs, fmt.Sprintf("0x%x", sa), fmt.Sprintf("0x%x", ((inst>>11)&0x1f)+1))
+		case 0x04:
+			return join("ins", rt, rs, fmt.Sprintf("0x%x", sa), fmt.Sprintf("0x%x", ((inst>>11)&0x1f)+1-sa))
+		case 0x20:
+			switch sa {
+			case 0x02:
+				return join("wsbh", rd, rt)
+			case 0x10:
+				return join("seb", rd, rt)
+			case 0x18:
+				return join("seh", rd, rt)
+			}
+		case 0x3b:
+			return join("rdhwr", rt, fmt.Sprintf("$%d", (inst>>11)&0x1f))
+		}
+
+	// COP1
+	case 0x11:
+		return decodeMIPSFPU(inst, rt, branch)
+	}
+
+	if op, ok := mipsImmediate[opcode]; ok {
+		if (op == "addiu" || op == "daddiu") && (inst>>21)&0x1f == 0 {
+			return join("li", rt, fmt.Sprintf("%d", imm))
+		}
+		if op == "andi" || op == "ori" || op == "xori" {
+			return join(op, rt, rs, fmt.Sprintf("0x%x", uimm))
+		}
+		return join(op, rt, rs, fmt.Sprintf("%d", imm))
+	}
+	if op, ok := mipsMemory[opcode]; ok {
+		return join(op, rt, fmt.Sprintf("%d(%s)", imm, rs))
+	}
+	if op, ok := mipsFPUMemory[opcode]; ok {
+		return join(op, fmt.Sprintf("$f%d", (inst>>16)&0x1f), fmt.Sprintf("%d(%s)", imm, rs))
+	}
+	return ""
+}
+
+// decodeMIPSFPU decodes a coprocessor 1 (floating point) instruction
+func decodeMIPSFPU(inst uint32, rt string, branch string) string {
+	fmtField := (inst >> 21) & 0x1f
+	ft := fmt.Sprintf("$f%d", (inst>>16)&0x1f)
+	fs := fmt.Sprintf("$f%d", (inst>>11)&0x1f)
+	fd := fmt.Sprintf("$f%d", (inst>>6)&0x1f)
+	funct := inst & 0x3f
+
+	switch fmtField {
+	case 0x00:
+		return "mfc1\t" + rt + "," + fs
+	case 0x01:
+		return "dmfc1\t" + rt + "," + fs
+	case 0x04:
+		return "mtc1\t" + rt + "," + fs
+	case 0x05:
+		return "dmtc1\t" + rt + "," + fs
+	case 0x08:
+		if (inst>>16)&0x1 == 1 {
+			return "bc1t\t" + branch
+		}
+		return "bc1f\t" + branch
+	}
+
+	format, ok := mipsFPUFormats[fmtField]
+	if !ok {
+		return ""
+	}
+	if funct >= 0x30 {
+		return "c." + mipsConditions[funct-0x30] + "." + format + "\t" + fs + "," + ft
+	}
+	op, ok := mipsFPU[funct]
+	if !ok {
+		return ""
+	}
+	switch funct {
+	case 0x00, 0x01, 0x02, 0x03:
+		return op + "." + format + "\t" + fd + "," + fs + "," + ft
+	}
+	return op + "." + format + "\t" + fd + "," + fs
+}
@@ -0,0 +1,82 @@
+package file
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestDecodeMIPS(t *testing.T) {
+	tests := []struct {
+		inst uint32
+		pc   uint64
+		is64 bool
+		want string
+	}{
+		// R format (SPECIAL)
+		{0x00000000, 0x400000, false, "nop"},
+		{0x00851021, 0x400000, false, "addu\tv0,a0,a1"},
+		{0x00801025, 0x400000, false, "move\tv0,a0"},
+		{0x00041080, 0x400000, false, "sll\tv0,a0,0x2"},
+		{0x00a41004, 0x400000, false, "sllv\tv0,a0,a1"},
+		{0x00850018, 0x400000, false, "mult\ta0,a1"},
+		{0x03e00008, 0x400000, false, "jr\tra"},
+		{0x0320f809, 0x400000, false, "jalr\tt9"},
+		{0x70851002, 0x400000, false, "mul\tv0,a0,a1"},
+		{0x7c8220c0, 0x400000, false, "ext\tv0,a0,0x3,0x5"},
+
+		// Registers 8-15 have other names in n64
+		{0x012a4021, 0x400000, false, "addu\tt0,t1,t2"},
+		{0x012a4021, 0x400000, true, "addu\ta4,a5,a6"},
+
+		// I format
+		{0x27bdffe0, 0x400000, false, "addiu\tsp,sp,-32"},
+		{0x67bdfff0, 0x400000, true, "daddiu\tsp,sp,-16"},
+		{0x24020001, 0x400000, false, "li\tv0,1"},
+		{0x3c1c0002, 0x400000, false, "lui\tgp,0x2"},
+		{0x3442ffff, 0x400000, false, "ori\tv0,v0,0xffff"},
+		{0x8fbf001c, 0x400000, false, "lw\tra,28(sp)"},
+		{0xafa4fffc, 0x400000, false, "sw\ta0,-4(sp)"},
+		{0xd4800008, 0x400000, false, "ldc1\t$f0,8(a0)"},
+
+		// Branches are relative to the delay slot (forward and back)
+		{0x10850004, 0x400000, false, "beq\ta0,a1,0x400014"},
+		{0x1440fffe, 0x400000, false, "bnez\tv0,0x3ffffc"},
+		{0x10000001, 0x400000, false, "b\t0x400008"},
+		{0x04800002, 0x400000, false, "bltz\ta0,0x40000c"},
+		{0x04110003, 0x400000, false, "bal\t0x400010"},
+
+		// J format jumps stay in the 256MB region of the delay slot
+		{0x08100010, 0x400000, false, "j\t0x400040"},
+		{0x0c100010, 0x400000, false, "jal\t0x400040"},
+		{0x08000010, 0x1ffffffc, false, "j\t0x20000040"},
+		{0x08000010, 0x2ffffff8, false, "j\t0x20000040"},
+		{0x08000010, 0x120000000, true, "j\t0x120000040"},
+
+		// FPU (COP1)
+		{0x46241000, 0x400000, false, "add.d\t$f0,$f2,$f4"},
+		{0x4622003c, 0x400000, false, "c.lt.d\t$f0,$f2"},
+		{0x45010002, 0x400000, false, "bc1t\t0x40000c"},
+		{0x44020000, 0x400000, false, "mfc1\tv0,$f0"},
+
+		// Unknown
+		{0xf8000000, 0x400000, false, ""},
+	}
+	for _, test := range tests {
+		code := make([]byte, 4)
+		binary.BigEndian.PutUint32(code, test.inst)
+		if got := decodeMIPS(code, test.pc, binary.BigEndian, test.is64); got != test.want {
+			t.Errorf("0x%08x at 0x%x: got %q, want %q", test.inst, test.pc, got, test.want)
+		}
+	}
+}
+
+// A little endian instruction is the same, with its bytes reversed
+func TestDecodeMIPSLittleEndian(t *testing.T) {
+	code := []byte{0xe0, 0xff, 0xbd, 0x27}
+	if got := decodeMIPS(code, 0x400000, binary.LittleEndian, false); got != "addiu\tsp,sp,-32" {
+		t.Errorf("got %q, want addiu\tsp,sp,-32", got)
+	}
+	if got := decodeMIPS(code[:3], 0x400000, binary.LittleEndian, false); got != "" {
+		t.Errorf("got %q for 3 bytes, want nothing", got)
+	}
+}
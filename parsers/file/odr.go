@@ -0,0 +1,74 @@
+package file
+
+import (
+	"sort"
+
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// The One Definition Rule says a type with external linkage must be defined
+// the same way in every compile unit. When two compile units disagree on the
+// layout of a type with the same name, code compiled from one of them reads
+// the other's objects wrong. Types in an anonymous namespace or a function
+// are local to a compile unit, so they are not compared.
+
+// An ODRViolation is a type defined with different layouts in different compile units
+type ODRViolation struct {
+	Name        string          `json:"name"`
+	Definitions []ODRDefinition `json:"definitions"`
+}
+
+// An ODRDefinition is one layout of a type, and the compile units that define it
+type ODRDefinition struct {
+	Kind         string   `json:"kind"`
+	Size         int64    `json:"size"`
+	Layout       string   `json:"layout"`
+	CompileUnits []string `json:"compile_units"`
+}
+
+// ODRViolations finds each type (struct, union, or class) defined with more
+// than one layout. Structurally identical definitions are counted once.
+func ODRViolations(d *dwarf.Data) []ODRViolation {
+
+	index := d.TypeIndex()
+
+	// The definitions of each name, by layout
+	definitions := map[string]map[string]*ODRDefinition{}
+	for name, offsets := range index.Definitions {
+		definitions[name] = map[string]*ODRDefinition{}
+		for _, offset := range offsets {
+			t, err := d.Type(offset)
+			if err != nil {
+				continue
+			}
+			convert, ok := t.(*dwarf.StructType)
+			if !ok || convert.Incomplete {
+				continue
+			}
+			layout := convert.Layout()
+			definition, ok := definitions[name][layout]
+			if !ok {
+				definition = &ODRDefinition{Kind: convert.Kind, Size: convert.ByteSize, Layout: layout}
+				definitions[name][layout] = definition
+			}
+			definition.CompileUnits = append(definition.CompileUnits, index.Units[convert.Unit])
+		}
+	}
+
+	violations := []ODRViolation{}
+	for name, layouts := range definitions {
+		if len(layouts) < 2 {
+			continue
+		}
+		violation := ODRViolation{Name: name}
+		for _, definition := range layouts {
+			violation.Definitions = append(violation.Definitions, *definition)
+		}
+		sort.Slice(violation.Definitions, func(i, j int) bool {
+			return violation.Definitions[i].Layout < violation.Definitions[j].Layout
+		})
+		violations = append(violations, violation)
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Name < violations[j].Name })
+	return violations
+}
@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"sort"
+	"strings"
 
 	"debug/gosym"
 	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
@@ -19,6 +20,16 @@ import (
 type File struct {
 	handle  *os.File
 	Entries []*Entry
+
+	// Debug information of other files to find type definitions in
+	extra []*dwarf.Data
+
+	// A separate debug file (e.g., of a stripped binary), see DebugFile
+	debug *File
+
+	// The debug information (with split units), read once by DWARF
+	dwarf    *dwarf.Data
+	dwarfErr error
 }
 
 // A generic Entry in a file has a name and data
@@ -42,6 +53,12 @@ type rawFile interface {
 	DynamicSymbols() (syms []Symbol, err error)
 	Symbols() (syms []Symbol, err error)
 	text() (textStart uint64, text []byte, err error)
+
+	// Where a separate debug file is, if the debug information was stripped
+	HasDwarf() bool
+	HasText() bool
+	BuildID() []byte
+	DebugLink() (name string, crc uint32, ok bool)
 }
 
 // LoadAddress returns the EXPECTED (not actual) address of the file.
@@ -81,6 +98,30 @@ var openers = []func(io.ReaderAt) (rawFile, error){
 
 // Open the named file (please close f.Close after finishing)
 func Open(name string) (*File, error) {
+	f, err := open(name)
+	if err != nil {
+		return nil, err
+	}
+	data := f.Entries[0].data
+	if !data.HasDwarf() {
+		f.debug = findDebugFile(name, data)
+		if f.debug == nil {
+			f.debug = debuginfodFile(data.BuildID(), "debuginfo")
+		}
+	} else if !data.HasText() {
+
+		// A separate debug file only has debug information, so the symbols and
+		// text are read from the executable (if a debuginfod server has it)
+		if exe := debuginfodFile(data.BuildID(), "executable"); exe != nil {
+			exe.debug = f
+			return exe, nil
+		}
+	}
+	return f, nil
+}
+
+// open the named file without looking for separate debug information
+func open(name string) (*File, error) {
 	handle, err := os.Open(name)
 	if err != nil {
 		return nil, err
@@ -93,7 +134,7 @@ func Open(name string) (*File, error) {
 	//}
 	for _, function := range openers {
 		if data, err := function(handle); err == nil {
-			return &File{handle, []*Entry{{data: data}}}, nil
+			return &File{handle: handle, Entries: []*Entry{{data: data}}}, nil
 		}
 	}
 	handle.Close()
@@ -102,6 +143,9 @@ func Open(name string) (*File, error) {
 
 // Close the file handle
 func (f *File) Close() error {
+	if f.debug != nil {
+		f.debug.Close()
+	}
 	return f.handle.Close()
 }
 
@@ -118,7 +162,16 @@ func (f *File) Symbols() ([]Symbol, error) {
 	return f.Entries[0].Symbols()
 }
 
+// PCLineTable prefers the line tables in the debug information (e.g., of C
+// code), and otherwise reads Go's pcln table
 func (f *File) PCLineTable() (Liner, error) {
+	if f.debug != nil || f.Entries[0].data.HasDwarf() {
+		if dwf, err := f.DWARF(); err == nil {
+			if liner, err := NewDwarfLiner(dwf); err == nil && liner != nil {
+				return liner, nil
+			}
+		}
+	}
 	return f.Entries[0].PCLineTable()
 }
 
@@ -132,12 +185,59 @@ func (f *File) LoadAddress() (uint64, error) {
 
 // Added back to support getting assembly to parse call sites
 func (f *File) Disasm() (*Disasm, error) {
-	return f.Entries[0].Disasm()
+	d, err := f.Entries[0].Disasm()
+	if err != nil {
+		return nil, err
+	}
+	d.pcln, err = f.PCLineTable()
+	return d, err
 }
 
-// Since this returns the top node (root), it returns all the dwarf
+// Since this returns the top node (root), it returns all the dwarf. It is
+// read (and split units are loaded) the first time, and kept for the next.
 func (f *File) DWARF() (*dwarf.Data, error) {
-	return f.Entries[0].Dwarf()
+	if f.dwarf == nil && f.dwarfErr == nil {
+		f.dwarf, f.dwarfErr = f.readDwarf()
+	}
+	if f.dwarfErr != nil {
+		return nil, f.dwarfErr
+	}
+	f.dwarf.Extra = f.extra
+	return f.dwarf, nil
+}
+
+// readDwarf reads the debug information of the file, or its separate debug file
+func (f *File) readDwarf() (*dwarf.Data, error) {
+	entry := f.Entries[0]
+	if f.debug != nil {
+		entry = f.debug.Entries[0]
+	} else if !entry.data.HasDwarf() {
+		return nil, fmt.Errorf("no debug information in %s, and no separate debug file was found (in %s)",
+			f.handle.Name(), strings.Join(DebugDirectories, ":"))
+	}
+	dwf, err := entry.Dwarf()
+	if err != nil {
+		return nil, err
+	}
+	loadSplitDwarf(dwf, f.handle.Name())
+	return dwf, nil
+}
+
+// AddDebugInfo reads the debug information of another binary or debug file
+// (e.g., a library this one uses), to find the definitions of types that are
+// only declared here (e.g., opaque handles)
+func (f *File) AddDebugInfo(name string) error {
+	other, err := Open(name)
+	if err != nil {
+		return err
+	}
+	defer other.Close()
+	dwf, err := other.DWARF()
+	if err != nil {
+		return fmt.Errorf("cannot read debug information from %s: %s", name, err)
+	}
+	f.extra = append(f.extra, dwf)
+	return nil
 }
 
 func (f *File) GetRelocations() []Relocation {
@@ -145,7 +245,7 @@ func (f *File) GetRelocations() []Relocation {
 }
 
 func (f *File) ParseDwarf() map[string]map[string]DwarfEntry {
-	dwf, err := f.Entries[0].Dwarf()
+	dwf, err := f.DWARF()
 	if err != nil {
 		log.Fatalf("Error parsing dwarf %v", err)
 	}
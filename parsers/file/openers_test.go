@@ -0,0 +1,21 @@
+package file
+
+import "testing"
+
+// The debug information is read once, and the same for each caller
+func TestDWARFIsKept(t *testing.T) {
+	f, err := Open(compile(t, "int one(void) { return 1; }\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	first, err := f.DWARF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.DWARF()
+	if err != nil || second != first {
+		t.Errorf("got %p (%v) the second time, want %p", second, err, first)
+	}
+}
@@ -0,0 +1,86 @@
+package file
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+	"github.com/vsoch/gosmeagle/pkg/debug/elf"
+)
+
+// A binary built with -gsplit-dwarf only has skeleton units, and the debug
+// information is in a .dwo file for each compile unit (found relative to the
+// compile directory), or in a .dwp package file next to the binary.
+
+// SplitDwarfPath is more directories to find .dwo and .dwp files in
+var SplitDwarfPath []string
+
+// loadSplitDwarf adds the split units of a binary's skeleton units
+func loadSplitDwarf(d *dwarf.Data, binary string) {
+	units := d.SplitUnits()
+	if len(units) == 0 {
+		return
+	}
+
+	// A package has all of the units (e.g., libfoo.so.dwp)
+	dirs := append([]string{filepath.Dir(binary)}, SplitDwarfPath...)
+	for _, dir := range dirs {
+		name := filepath.Join(dir, filepath.Base(binary)+".dwp")
+		if !exists(name) {
+			continue
+		}
+		if err := addSplitDwarf(d, name, d.AddPackage); err != nil {
+			log.Printf("Cannot read split DWARF package %s: %s\n", name, err)
+		}
+		break
+	}
+
+	for _, unit := range d.SplitUnits() {
+		candidates := []string{}
+		if filepath.IsAbs(unit.Name) {
+			candidates = append(candidates, unit.Name)
+		} else if unit.CompDir != "" {
+			candidates = append(candidates, filepath.Join(unit.CompDir, unit.Name))
+		}
+		for _, dir := range dirs {
+			candidates = append(candidates, filepath.Join(dir, unit.Name), filepath.Join(dir, filepath.Base(unit.Name)))
+		}
+
+		found := false
+		for _, name := range candidates {
+			if !exists(name) {
+				continue
+			}
+			if err := addSplitDwarf(d, name, d.AddSplitUnits); err != nil {
+				log.Printf("Cannot read split DWARF %s: %s\n", name, err)
+				continue
+			}
+			found = true
+			break
+		}
+		if !found {
+			log.Printf("Cannot find split DWARF %s (in %s)\n", unit.Name, unit.CompDir)
+		}
+	}
+}
+
+// addSplitDwarf reads the sections of a .dwo or .dwp file and adds them
+func addSplitDwarf(d *dwarf.Data, name string, add func(map[string][]byte) error) error {
+	f, err := elf.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sections, err := f.DWOSections()
+	if err != nil {
+		return err
+	}
+	return add(sections)
+}
+
+// exists determines if a path is a file
+func exists(name string) bool {
+	info, err := os.Stat(name)
+	return err == nil && !info.IsDir()
+}
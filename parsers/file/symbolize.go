@@ -0,0 +1,273 @@
+package file
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// An address (e.g., from a crash report) is symbolized with the symbol it is
+// in, and with the functions it is in according to the debug information:
+// the subprogram, and each inlined subroutine (DW_TAG_inlined_subroutine) in
+// it that covers the address. The innermost function is at the file and line
+// of the address, and each function it was inlined into is at the call.
+
+// A Symbolized address, with its frames (innermost first)
+type Symbolized struct {
+	Address string  `json:"address"`
+	Symbol  string  `json:"symbol,omitempty"`
+	Offset  string  `json:"offset,omitempty"`
+	Frames  []Frame `json:"frames"`
+}
+
+// A Frame is a function the address is in, and where in the source
+type Frame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Inlined  bool   `json:"inlined,omitempty"`
+}
+
+// A Symbolizer looks up addresses in one file
+type Symbolizer struct {
+	syms   []Symbol
+	dwf    *dwarf.Data
+	reader *dwarf.Reader
+}
+
+// NewSymbolizer reads the symbols and debug information of a file (an address
+// can still be symbolized with only one of them)
+func (f *File) NewSymbolizer() (*Symbolizer, error) {
+	s := &Symbolizer{}
+	syms, _ := f.Symbols()
+	dyns, _ := f.DynamicSymbols()
+	for _, sym := range append(syms, dyns...) {
+		if (sym.GetCode() == 'T' || sym.GetCode() == 't') && sym.GetAddress() != 0 {
+			s.syms = append(s.syms, sym)
+		}
+	}
+	sort.Sort(SortByAddress(s.syms))
+
+	if dwf, err := f.DWARF(); err == nil {
+		s.dwf = dwf
+		s.reader = dwf.Reader()
+	}
+	if len(s.syms) == 0 && s.dwf == nil {
+		return nil, fmt.Errorf("no symbols or debug information")
+	}
+	return s, nil
+}
+
+// Symbolize returns the symbol and frames of an address
+func (s *Symbolizer) Symbolize(pc uint64) Symbolized {
+	result := Symbolized{Address: fmt.Sprintf("%#x", pc), Frames: []Frame{}}
+	if sym := s.lookup(pc); sym != nil {
+		result.Symbol = sym.GetName()
+		result.Offset = fmt.Sprintf("%#x", pc-sym.GetAddress())
+	}
+	if s.dwf != nil {
+		result.Frames = s.frames(pc)
+	}
+	return result
+}
+
+// lookup finds the symbol containing pc
+func (s *Symbolizer) lookup(pc uint64) Symbol {
+	i := sort.Search(len(s.syms), func(i int) bool { return pc < s.syms[i].GetAddress() })
+	for i > 0 {
+		i--
+		sym := s.syms[i]
+		if pc < sym.GetAddress()+uint64(sym.GetSize()) {
+			return sym
+		}
+
+		// An alias (at the same address) might have the size
+		if i > 0 && s.syms[i-1].GetAddress() == sym.GetAddress() {
+			continue
+		}
+		break
+	}
+	return nil
+}
+
+// frames returns the function and inlined calls at pc, innermost first
+func (s *Symbolizer) frames(pc uint64) []Frame {
+	unit, chain := s.scope(pc)
+	if unit == nil {
+		return []Frame{}
+	}
+
+	// The line table has the files that calls are in, and the line of pc
+	var files []*dwarf.LineFile
+	var location dwarf.LineEntry
+	found := false
+	if lines, err := s.dwf.LineReader(unit); err == nil && lines != nil {
+		found = lines.SeekPC(pc, &location) == nil || scanPC(lines, pc, &location)
+		files = lines.Files()
+	}
+
+	frames := []Frame{}
+	for i := len(chain) - 1; i >= 0; i-- {
+		frame := Frame{Function: s.functionName(chain[i]), Inlined: i > 0}
+
+		if i == len(chain)-1 {
+			if found {
+				frame.Line, frame.Column = location.Line, location.Column
+				if location.File != nil {
+					frame.File = location.File.Name
+				}
+			}
+		} else {
+			call := chain[i+1]
+			if line := intValue(call, dwarf.AttrCallLine); line > 0 {
+				frame.Line = int(line)
+			}
+			if column := intValue(call, dwarf.AttrCallColumn); column > 0 {
+				frame.Column = int(column)
+			}
+			if index := intValue(call, dwarf.AttrCallFile); index >= 0 && index < int64(len(files)) && files[index] != nil {
+				frame.File = files[index].Name
+			}
+		}
+		frames = append(frames, frame)
+	}
+
+	// Without a function, the line table can still say where pc is
+	if len(chain) == 0 && found && location.File != nil {
+		frames = append(frames, Frame{File: location.File.Name, Line: location.Line, Column: location.Column})
+	}
+	return frames
+}
+
+// scope returns the compile unit of pc, and the subprogram and inlined
+// subroutines (outermost first) that cover it
+func (s *Symbolizer) scope(pc uint64) (*dwarf.Entry, []*dwarf.Entry) {
+	unit, err := s.reader.SeekPC(pc)
+	if err != nil {
+		return nil, nil
+	}
+	chain := s.walk(pc, nil)
+	if len(chain) > 0 {
+		return unit, chain
+	}
+
+	// A skeleton unit covers pc, but its split unit has the functions (and
+	// the ranges of units can overlap), so every unit is searched. A split
+	// unit uses the line table of its skeleton.
+	s.reader.Seek(0)
+	for entry, err := s.reader.Next(); entry != nil && err == nil; entry, err = s.reader.Next() {
+		if !entry.Children {
+			continue
+		}
+		if chain := s.walk(pc, nil); len(chain) > 0 {
+			if _, ok := entry.Val(dwarf.AttrStmtList).(int64); ok {
+				return entry, chain
+			}
+			return unit, chain
+		}
+	}
+	return unit, nil
+}
+
+// walk reads the children of the current entry, descending into those that
+// cover pc, and returns the chain of functions
+func (s *Symbolizer) walk(pc uint64, chain []*dwarf.Entry) []*dwarf.Entry {
+	for entry, err := s.reader.Next(); entry != nil && err == nil; entry, err = s.reader.Next() {
+		if entry.Tag == 0 {
+			return chain
+		}
+		switch entry.Tag {
+		case dwarf.TagSubprogram, dwarf.TagInlinedSubroutine, dwarf.TagLexDwarfBlock:
+			if !s.covers(entry, pc) {
+				break
+			}
+			if entry.Tag != dwarf.TagLexDwarfBlock {
+				chain = append(chain, entry)
+			}
+			if !entry.Children {
+				return chain
+			}
+			return s.walk(pc, chain)
+
+		// Functions can be defined in a namespace or module
+		case dwarf.TagNamespace, dwarf.TagModule:
+			if !entry.Children {
+				continue
+			}
+			if found := s.walk(pc, chain); len(found) > len(chain) {
+				return found
+			}
+			continue
+		}
+		if entry.Children {
+			s.reader.SkipChildren()
+		}
+	}
+	return chain
+}
+
+// covers determines if an entry's ranges include pc
+func (s *Symbolizer) covers(entry *dwarf.Entry, pc uint64) bool {
+	ranges, err := s.dwf.Ranges(entry)
+	if err != nil {
+		return false
+	}
+	for _, r := range ranges {
+		if r[0] <= pc && pc < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// functionName returns the name of a subprogram or inlined subroutine, which
+// can be on the declaration (DW_AT_specification) or abstract instance
+// (DW_AT_abstract_origin) it refers to
+func (s *Symbolizer) functionName(entry *dwarf.Entry) string {
+	reader := s.dwf.Reader()
+	for i := 0; entry != nil && i < 8; i++ {
+		if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+			return name
+		}
+		if name, ok := entry.Val(dwarf.AttrLinkageName).(string); ok {
+			return name
+		}
+		offset, ok := entry.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+		if !ok {
+			offset, ok = entry.Val(dwarf.AttrSpecification).(dwarf.Offset)
+		}
+		if !ok {
+			return ""
+		}
+		reader.Seek(offset)
+		entry, _ = reader.Next()
+	}
+	return ""
+}
+
+// scanPC finds the row of pc by reading the whole line table, since SeekPC
+// stops at a sequence that starts after pc (but sequences aren't sorted,
+// e.g., a function with a .cold part)
+func scanPC(lines *dwarf.LineReader, pc uint64, entry *dwarf.LineEntry) bool {
+	lines.Reset()
+	var last, next dwarf.LineEntry
+	started := false
+	for lines.Next(&next) == nil {
+		if started && !last.EndSequence && last.Address <= pc && pc < next.Address {
+			*entry = last
+			return true
+		}
+		last, started = next, true
+	}
+	return false
+}
+
+// intValue returns a constant attribute, or -1 if there is none
+func intValue(entry *dwarf.Entry, attr dwarf.Attr) int64 {
+	if value, ok := entry.Val(attr).(int64); ok {
+		return value
+	}
+	return -1
+}
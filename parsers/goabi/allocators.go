@@ -0,0 +1,140 @@
+package goabi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/parsers/x86_64"
+)
+
+// Register sequences for ABIInternal, in the order Go assigns them
+// https://go.googlesource.com/go/+/refs/heads/master/src/cmd/compile/abi-internal.md
+var intRegisters = map[string][]string{
+	"amd64": {"%rax", "%rbx", "%rcx", "%rdi", "%rsi", "%r8", "%r9", "%r10", "%r11"},
+	"arm64": {"x0", "x1", "x2", "x3", "x4", "x5", "x6", "x7", "x8", "x9", "x10", "x11", "x12", "x13", "x14", "x15"},
+}
+
+var floatRegisters = map[string][]string{
+	"amd64": {"%xmm0", "%xmm1", "%xmm2", "%xmm3", "%xmm4", "%xmm5", "%xmm6", "%xmm7", "%xmm8", "%xmm9",
+		"%xmm10", "%xmm11", "%xmm12", "%xmm13", "%xmm14"},
+	"arm64": {"d0", "d1", "d2", "d3", "d4", "d5", "d6", "d7", "d8", "d9", "d10", "d11", "d12", "d13", "d14", "d15"},
+}
+
+// The pointer size of each architecture with ABIInternal registers
+var ptrSizes = map[string]int64{
+	"amd64": 8,
+	"arm64": 8,
+}
+
+// dwarfRegisters names DWARF register numbers, to describe compiler locations
+func dwarfRegisters(goarch string) map[int]string {
+	if goarch == "amd64" {
+		return x86_64.DwarfRegisters
+	}
+	registers := map[int]string{}
+	if goarch == "arm64" {
+		for i := 0; i < 31; i++ {
+			registers[i] = fmt.Sprintf("x%d", i)
+		}
+		registers[31] = "sp"
+		for i := 0; i < 32; i++ {
+			registers[64+i] = fmt.Sprintf("v%d", i)
+		}
+	}
+	return registers
+}
+
+// Supported returns true if we know the ABIInternal registers for an architecture
+func Supported(goarch string) bool {
+	_, ok := intRegisters[goarch]
+	_, hasPtrSize := ptrSizes[goarch]
+	return ok && hasPtrSize
+}
+
+// A StackAllocator lays out stack assigned values in the argument area
+type StackAllocator struct {
+	Offset  int64 // offset into the argument area
+	PtrSize int64
+}
+
+// NewStackAllocator creates a new stack allocator. The return address (or the
+// saved link register slot on arm64) sits below the arguments, so the first
+// argument is found one pointer above the frame base.
+func NewStackAllocator(ptrSize int64) *StackAllocator {
+	return &StackAllocator{PtrSize: ptrSize}
+}
+
+// align rounds the offset up to a multiple of align
+func (s *StackAllocator) align(align int64) {
+	if align > 1 {
+		s.Offset = (s.Offset + align - 1) &^ (align - 1)
+	}
+}
+
+// Next reserves space for a value and returns its offset
+func (s *StackAllocator) Next(size int64, align int64) int64 {
+	s.align(align)
+	offset := s.Offset
+	s.Offset += size
+	return offset
+}
+
+// Location formats an offset in the argument area as a framebase location
+func (s *StackAllocator) Location(offset int64) string {
+	return "framebase+" + fmt.Sprintf("%d", offset+s.PtrSize)
+}
+
+// A RegisterAllocator hands out ABIInternal registers in order
+type RegisterAllocator struct {
+	IntRegisters   []string
+	FloatRegisters []string
+	nextInt        int
+	nextFloat      int
+	Stack          *StackAllocator
+}
+
+// NewRegisterAllocator creates a new register allocator for an architecture
+func NewRegisterAllocator(goarch string, ptrSize int64) *RegisterAllocator {
+	return &RegisterAllocator{IntRegisters: intRegisters[goarch], FloatRegisters: floatRegisters[goarch],
+		Stack: NewStackAllocator(ptrSize)}
+}
+
+// Reset starts handing out registers from the beginning again. Results are
+// assigned registers independently of the arguments, but share the stack.
+func (r *RegisterAllocator) Reset() {
+	r.nextInt = 0
+	r.nextFloat = 0
+}
+
+// Fits determines if a sequence of register classes fits in what is left
+func (r *RegisterAllocator) Fits(classes []RegisterClass) bool {
+	ints, floats := 0, 0
+	for _, cls := range classes {
+		if cls == INTEGER {
+			ints++
+		} else {
+			floats++
+		}
+	}
+	return r.nextInt+ints <= len(r.IntRegisters) && r.nextFloat+floats <= len(r.FloatRegisters)
+}
+
+// Assign returns the registers for a sequence of classes. Callers must check Fits first.
+func (r *RegisterAllocator) Assign(classes []RegisterClass) []string {
+	regs := []string{}
+	for _, cls := range classes {
+		if cls == INTEGER {
+			regs = append(regs, r.IntRegisters[r.nextInt])
+			r.nextInt++
+		} else {
+			regs = append(regs, r.FloatRegisters[r.nextFloat])
+			r.nextFloat++
+		}
+	}
+	return regs
+}
+
+// JoinRegisters combines registers for a value that spans more than one
+func JoinRegisters(regs []string) string {
+	return strings.Join(regs, " | ")
+}
@@ -0,0 +1,102 @@
+package goabi
+
+// Go's ABIInternal decomposes each value into a sequence of integer and
+// floating point registers, recursing into structs and arrays of length 1.
+
+import (
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+type RegisterClass int
+
+const (
+	INTEGER RegisterClass = iota // Fits in an integer register
+	FLOAT                        // Fits in a floating point register
+)
+
+func (r RegisterClass) String() string {
+	switch r {
+	case INTEGER:
+		return "INTEGER"
+	case FLOAT:
+		return "FLOAT"
+	}
+	return "UNKNOWN"
+}
+
+// Classify returns the register classes a value needs, in order. If the value
+// cannot be register assigned (e.g., an array with more than one element)
+// ok is false and the whole value is passed on the stack.
+func Classify(t dwarf.Type, ptrSize int64) (classes []RegisterClass, ok bool) {
+	switch convert := t.(type) {
+	case *dwarf.TypedefType:
+		return Classify(convert.Type, ptrSize)
+	case *dwarf.QualType:
+		return Classify(convert.Type, ptrSize)
+	case *dwarf.VoidType:
+		return []RegisterClass{}, true
+	case *dwarf.FloatType:
+		return []RegisterClass{FLOAT}, true
+	case *dwarf.ComplexType:
+		return []RegisterClass{FLOAT, FLOAT}, true
+	case *dwarf.StructType:
+		classes = []RegisterClass{}
+		for _, field := range convert.Field {
+			fieldClasses, ok := Classify(field.Type, ptrSize)
+			if !ok {
+				return nil, false
+			}
+			classes = append(classes, fieldClasses...)
+		}
+		return classes, true
+	case *dwarf.ArrayType:
+		switch convert.Count {
+		case 0:
+			return []RegisterClass{}, true
+		case 1:
+			return Classify(convert.Type, ptrSize)
+		}
+		return nil, false
+	}
+
+	// Everything else (integers, bools, pointers, maps, channels, funcs) is
+	// integer, and 64-bit integers on 32-bit platforms take two registers
+	size := t.Size()
+	if size == 0 {
+		return []RegisterClass{}, true
+	}
+	if size > ptrSize {
+		return []RegisterClass{INTEGER, INTEGER}, true
+	}
+	return []RegisterClass{INTEGER}, true
+}
+
+// Alignment returns the alignment Go uses for a type in the argument area
+func Alignment(t dwarf.Type, ptrSize int64) int64 {
+	switch convert := t.(type) {
+	case *dwarf.TypedefType:
+		return Alignment(convert.Type, ptrSize)
+	case *dwarf.QualType:
+		return Alignment(convert.Type, ptrSize)
+	case *dwarf.ArrayType:
+		return Alignment(convert.Type, ptrSize)
+	case *dwarf.ComplexType:
+		return convert.Size() / 2
+	case *dwarf.StructType:
+		align := int64(1)
+		for _, field := range convert.Field {
+			if fieldAlign := Alignment(field.Type, ptrSize); fieldAlign > align {
+				align = fieldAlign
+			}
+		}
+		return align
+	}
+	size := t.Size()
+	if size <= 0 {
+		return 1
+	}
+	if size > ptrSize {
+		return ptrSize
+	}
+	return size
+}
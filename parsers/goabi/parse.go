@@ -0,0 +1,178 @@
+package goabi
+
+import (
+	"strings"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/parsers/x86_64"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// The linker names the stack-based wrapper of a register-based function <name>.abi0
+const abi0Suffix = ".abi0"
+
+// WrappedName returns the function an ABI0 wrapper symbol calls into
+func WrappedName(name string) (string, bool) {
+	if strings.HasSuffix(name, abi0Suffix) {
+		return strings.TrimSuffix(name, abi0Suffix), true
+	}
+	return name, false
+}
+
+// ParseFunction parses the parameters of a function compiled by the Go toolchain
+func ParseFunction(f *file.File, symbol file.Symbol, entry *file.DwarfEntry, disasm *file.Disasm, isCallSite bool) descriptor.FunctionDescription {
+
+	ptrSize := ptrSizes[f.GoArch()]
+	allocator := NewRegisterAllocator(f.GoArch(), ptrSize)
+	direction := x86_64.GetDirection(symbol.GetName(), isCallSite)
+
+	// ABI0 passes everything on the stack, and is used by assembly and wrappers
+	wraps, isWrapper := WrappedName(symbol.GetName())
+	convention := "ABIInternal"
+	if isWrapper {
+		convention = "ABI0"
+	} else {
+		wraps = ""
+	}
+
+	// Go describes results as formal parameters, so split them out
+	args := []file.Component{}
+	results := []file.Component{}
+	for _, c := range (*entry).GetComponents() {
+		if c.VarParam || c.Name == "return" {
+			results = append(results, c)
+		} else {
+			args = append(args, c)
+		}
+	}
+
+	registers := dwarfRegisters(f.GoArch())
+	params := []descriptor.Parameter{}
+	for _, c := range args {
+		param := parseValue(c, "", allocator, isWrapper, isCallSite)
+		if param != nil {
+			params = append(params, descriptor.WithDwarfLocation(param, file.DescribeLocation(c.Location, registers)))
+		}
+	}
+
+	// Results start over at the first register, and follow the arguments on the stack
+	allocator.Reset()
+	allocator.Stack.align(ptrSize)
+	for _, c := range results {
+		param := parseValue(c, "return", allocator, isWrapper, isCallSite)
+		if param != nil {
+			params = append(params, param)
+		}
+	}
+	return descriptor.FunctionDescription{Parameters: params, Name: symbol.GetName(), Type: "Function", Direction: direction,
+		CallingConvention: convention, Wraps: wraps}
+}
+
+// parseValue assigns registers (or stack) to one argument or result
+func parseValue(c file.Component, role string, a *RegisterAllocator, stackOnly bool, isCallSite bool) descriptor.Parameter {
+
+	t, ok := c.RawType.(dwarf.Type)
+	if !ok {
+		return nil
+	}
+	if role == "" {
+		role = c.Name
+	}
+	direction := x86_64.GetDirection(role, isCallSite)
+
+	classes, ok := Classify(t, a.Stack.PtrSize)
+	if ok && !stackOnly && a.Fits(classes) {
+		regs := a.Assign(classes)
+		return describe(c.Name, t, &regs, 0, a.Stack, direction)
+	}
+	offset := a.Stack.Next(t.Size(), Alignment(t, a.Stack.PtrSize))
+	return describe(c.Name, t, nil, offset, a.Stack, direction)
+}
+
+// describe builds a parameter for a value. When regs is nil the value lives on
+// the stack at offset, otherwise each register class takes the next register.
+func describe(name string, t dwarf.Type, regs *[]string, offset int64, s *StackAllocator, direction string) descriptor.Parameter {
+
+	// Take the locations for a value that needs count registers
+	location := func(count int) string {
+		if regs == nil {
+			return s.Location(offset)
+		}
+		if count > len(*regs) {
+			count = len(*regs)
+		}
+		taken := (*regs)[:count]
+		*regs = (*regs)[count:]
+		return JoinRegisters(taken)
+	}
+
+	switch convert := t.(type) {
+	case *dwarf.TypedefType:
+		// Named Go types are typedefs, keep the name but describe the layout
+		return describe(name, convert.Type, regs, offset, s, direction)
+
+	case *dwarf.QualType:
+		return describe(name, convert.Type, regs, offset, s, direction)
+
+	case *dwarf.StructType:
+		fields := []descriptor.Parameter{}
+		consumed := []string{}
+		for _, field := range convert.Field {
+			// The field takes registers from the front, so the struct can list them
+			var remaining []string
+			if regs != nil {
+				remaining = *regs
+			}
+			newField := describe(field.Name, field.Type, regs, offset+field.ByteOffset, s, direction)
+			if regs != nil {
+				consumed = append(consumed, remaining[:len(remaining)-len(*regs)]...)
+			}
+			if newField != nil {
+				fields = append(fields, newField)
+			}
+		}
+		loc := s.Location(offset)
+		if regs != nil {
+			loc = JoinRegisters(consumed)
+		}
+		return descriptor.StructureParameter{Name: name, Type: convert.StructName, Class: "Struct", Size: convert.Size(),
+			Direction: direction, Location: loc, Fields: fields}
+
+	case *dwarf.ArrayType:
+		var item descriptor.Parameter
+		loc := s.Location(offset)
+		if convert.Count == 1 {
+			item = describe("", convert.Type, regs, offset, s, direction)
+			if regs != nil {
+				loc = item.GetLocation()
+			}
+		} else {
+			item = underlying(convert.Type)
+		}
+		return descriptor.ArrayParameter{Name: name, Type: convert.Type.String(), Class: "Array", Size: convert.Size(),
+			Length: convert.Count, Location: loc, Direction: direction, ItemType: item}
+
+	case *dwarf.PtrType:
+		return descriptor.PointerParameter{Name: name, Type: convert.String(), Class: "Pointer", Size: convert.Size(),
+			Location: location(1), Direction: direction, UnderlyingType: underlying(convert.Type), Indirections: 1}
+
+	case *dwarf.VoidType:
+		return nil
+	}
+
+	classes, _ := Classify(t, s.PtrSize)
+	if len(classes) == 0 {
+		return nil
+	}
+	return descriptor.BasicParameter{Name: name, Type: t.Common().Name, Class: file.GetStringType(t), Size: t.Size(),
+		Location: location(len(classes)), Direction: direction}
+}
+
+// underlying gives a shallow description of what a pointer or array holds
+func underlying(t dwarf.Type) descriptor.Parameter {
+	if _, ok := t.(*dwarf.VoidType); ok {
+		return descriptor.BasicParameter{Type: "void", Class: "Void"}
+	}
+	return descriptor.BasicParameter{Type: t.String(), Class: file.GetStringType(t), Size: t.Size()}
+}
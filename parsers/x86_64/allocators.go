@@ -3,6 +3,10 @@ package x86_64
 import (
 	"fmt"
 	"log"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
 )
 
 // A FramebaseAllocator keeps track of framebase index
@@ -49,7 +53,7 @@ func NewRegisterAllocator() *RegisterAllocator {
 	// Populate the sse register stack
 	sse := []string{}
 	for i := 7; i >= 0; i-- {
-		sse = append(sse, "%xmm"+string(i))
+		sse = append(sse, "%xmm"+fmt.Sprintf("%d", i))
 	}
 
 	// Populate the int register stack
@@ -61,6 +65,42 @@ func NewRegisterAllocator() *RegisterAllocator {
 
 }
 
+// NewReturnAllocator creates a Register Allocator for a return value, which
+// is returned in %rax and %rdx (or %xmm0 and %xmm1)
+func NewReturnAllocator() *RegisterAllocator {
+	a := NewRegisterAllocator()
+	a.IntRegisters = []string{"%rdx", "%rax"}
+	a.SseRegisters = []string{"%xmm1", "%xmm0"}
+	return a
+}
+
+// ReturnAllocator creates a Register Allocator for the return value of a
+// function. An aggregate classified MEMORY is returned in memory, and its
+// address is passed first (so it takes the first integer register of the
+// parameters).
+func ReturnAllocator(c file.Component, params *RegisterAllocator) *RegisterAllocator {
+	a := NewReturnAllocator()
+	t, ok := c.RawType.(dwarf.Type)
+	for ok {
+		switch convert := t.(type) {
+		case *dwarf.TypedefType:
+			t = convert.Type
+		case *dwarf.QualType:
+			t = convert.Type
+		default:
+			ok = false
+		}
+	}
+	switch t.(type) {
+	case *dwarf.StructType, *dwarf.ArrayType:
+		if ClassifyAggregate(t, c.Class).Lo == MEMORY {
+			params.getNextIntRegister()
+			a.IntRegisters, a.SseRegisters = nil, nil
+		}
+	}
+	return a
+}
+
 // getNextIntRegister gets the next available integer register
 func (r *RegisterAllocator) getNextIntRegister() string {
 
@@ -91,7 +131,7 @@ func (r *RegisterAllocator) getNextSseRegister() string {
 	regString := r.SseRegisters[top]
 
 	// And update to remove it
-	r.IntRegisters = append(r.SseRegisters[:top], r.SseRegisters[top+1:]...)
+	r.SseRegisters = append(r.SseRegisters[:top], r.SseRegisters[top+1:]...)
 	return regString
 }
 
@@ -154,3 +194,41 @@ func (r *RegisterAllocator) GetRegisterString(lo RegisterClass, hi RegisterClass
 	log.Fatalf("Unknown classification")
 	return "unknown"
 }
+
+// GetAggregateString allocates a register for each eightbyte of an aggregate
+// ("%rdi | %rsi"). If there are not enough registers for all of them, the
+// whole aggregate goes on the stack.
+func (r *RegisterAllocator) GetAggregateString(lo RegisterClass, hi RegisterClass, size int64) string {
+	classes := []RegisterClass{lo}
+	if size > 8 {
+		classes = append(classes, hi)
+	}
+	ints, sses := 0, 0
+	for _, class := range classes {
+		switch class {
+		case INTEGER:
+			ints++
+		case SSE:
+			sses++
+		case NO_CLASS:
+		default:
+			return r.Fallocator.NextFramebaseFromSize(size)
+		}
+	}
+	if ints > len(r.IntRegisters) || sses > len(r.SseRegisters) {
+		return r.Fallocator.NextFramebaseFromSize(size)
+	}
+	registers := []string{}
+	for _, class := range classes {
+		switch class {
+		case INTEGER:
+			registers = append(registers, r.getNextIntRegister())
+		case SSE:
+			registers = append(registers, r.getNextSseRegister())
+		}
+	}
+	if len(registers) == 0 {
+		return "none"
+	}
+	return strings.Join(registers, " | ")
+}
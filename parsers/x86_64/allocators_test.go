@@ -0,0 +1,88 @@
+package x86_64
+
+import (
+	"testing"
+
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// Types to build the return values of the tests from
+var (
+	charType       = &dwarf.CharType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 1, Name: "char"}}}
+	intType        = &dwarf.IntType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 4, Name: "int"}}}
+	longType       = &dwarf.IntType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 8, Name: "long int"}}}
+	floatType      = &dwarf.FloatType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 4, Name: "float"}}}
+	longDoubleType = &dwarf.FloatType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: 16, Name: "long double"}}}
+)
+
+// structType makes a structure (or union) of fields at the given offsets
+func structType(kind string, size int64, fields ...*dwarf.StructField) *dwarf.StructType {
+	return &dwarf.StructType{CommonType: dwarf.CommonType{ByteSize: size}, StructName: "s", Kind: kind, Field: fields}
+}
+
+func field(t dwarf.Type, offset int64) *dwarf.StructField {
+	return &dwarf.StructField{Name: "f", Type: t, ByteOffset: offset}
+}
+
+func TestReturnAllocator(t *testing.T) {
+	big := structType("struct", 24, field(longType, 0), field(longType, 8), field(longType, 16))
+	tagged := structType("struct", 24)
+	tagged.Variants = &dwarf.VariantPart{Discriminant: field(longType, 0),
+		Variants: []*dwarf.Variant{{Field: []*dwarf.StructField{field(longType, 8), field(longType, 16)}}}}
+
+	tests := []struct {
+		name     string
+		class    string
+		typ      dwarf.Type
+		inMemory bool
+	}{
+		{"long", "Int", longType, false},
+		{"two longs", "Struct", structType("struct", 16, field(longType, 0), field(longType, 8)), false},
+		{"floats", "Struct", structType("struct", 8, field(floatType, 0), field(floatType, 4)), false},
+		{"long double", "Struct", structType("struct", 16, field(longDoubleType, 0)), false},
+		{"three longs", "Struct", big, true},
+		{"packed", "Struct", structType("struct", 5, field(charType, 0), field(intType, 1)), true},
+		{"long double and int", "Union", structType("union", 16, field(longDoubleType, 0), field(intType, 0)), true},
+		{"typedef", "Typedef", &dwarf.TypedefType{CommonType: dwarf.CommonType{Name: "big_t"},
+			Type: &dwarf.QualType{Qual: "const", Type: big}}, true},
+		{"array", "Array", &dwarf.ArrayType{CommonType: dwarf.CommonType{ByteSize: 32}, Type: longType, Count: 4}, true},
+		{"tagged union", "Structure", tagged, true},
+	}
+	for _, test := range tests {
+		params := NewRegisterAllocator()
+		a := ReturnAllocator(file.Component{Name: "return", Class: test.class, Size: test.typ.Size(), RawType: test.typ}, params)
+
+		// The address of a value returned in memory is the first parameter
+		first := params.getNextIntRegister()
+		if test.inMemory && (first != "%rsi" || len(a.IntRegisters) != 0) {
+			t.Errorf("%s: got first parameter %s and return registers %v, want %%rsi and none", test.name, first, a.IntRegisters)
+		}
+		if !test.inMemory && (first != "%rdi" || a.getNextIntRegister() != "%rax") {
+			t.Errorf("%s: got first parameter %s, want %%rdi and a return value in %%rax", test.name, first)
+		}
+	}
+}
+
+// Floats take SSE registers in order, and leave the integer registers alone
+func TestSseRegisters(t *testing.T) {
+	a := NewRegisterAllocator()
+	got := []string{
+		a.GetRegisterString(SSE, NO_CLASS, 8, "Float"),
+		a.GetRegisterString(SSE, NO_CLASS, 4, "Float"),
+		a.GetRegisterString(INTEGER, NO_CLASS, 8, "Int"),
+	}
+	want := []string{"%xmm0", "%xmm1", "%rdi"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+	for i := 2; i < 8; i++ {
+		a.GetRegisterString(SSE, NO_CLASS, 8, "Float")
+	}
+	if loc := a.GetRegisterString(SSE, NO_CLASS, 8, "Float"); loc != "framebase+8" {
+		t.Errorf("got %s for a ninth float, want the stack", loc)
+	}
+}
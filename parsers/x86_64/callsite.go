@@ -0,0 +1,211 @@
+package x86_64
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/descriptor"
+	"github.com/vsoch/gosmeagle/parsers/file"
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// ParseCallSite describes a call made by caller
+func ParseCallSite(caller string, cs *file.CallSite) descriptor.CallSiteDescription {
+	call := descriptor.CallSiteDescription{Caller: caller, Callee: cs.Callee(), TailCall: cs.TailCall(), Type: "CallSite"}
+	if pc, ok := cs.ReturnPC(); ok {
+		call.ReturnPC = file.Address(pc)
+	}
+	if call.Callee == "" {
+		call.Indirect = true
+		call.Target, _ = ValueString(cs.Target())
+		if t := cs.TargetType(); t != nil {
+			call.TargetType = t.String()
+		}
+	}
+
+	for i := range cs.Params {
+		param := &cs.Params[i]
+		arg := descriptor.CallSiteArgument{Name: cs.ArgumentName(param)}
+		arg.Location, _ = CallLocationString(cs.ArgumentLocation(param))
+		argValue := cs.ArgumentValue(param)
+		value, constant := ValueString(argValue)
+		if constant && strings.HasPrefix(arg.Location, "%xmm") {
+			if float, ok := floatString(argValue, sseArgumentSize(cs, param, argValue, arg.Location)); ok {
+				value = float
+			}
+		}
+		if constant {
+			arg.Value = value
+		} else {
+			arg.Expression = value
+		}
+		call.Arguments = append(call.Arguments, arg)
+	}
+	return call
+}
+
+// CallLocationString converts where an argument is at a call to the syntax we
+// predict for the function called: registers are "%rdi", and the stack slot at
+// the stack pointer is "framebase+8" (the return address is pushed first)
+func CallLocationString(loc *dwarf.Location) (string, bool) {
+	if loc == nil || len(loc.Pieces) == 0 {
+		return "", false
+	}
+	names := []string{}
+	for _, piece := range loc.Pieces {
+		name := ""
+		switch {
+		case piece.Kind == dwarf.PieceRegister:
+			name = DwarfRegisters[piece.Register]
+		case piece.Kind == dwarf.PieceMemory && piece.Base == dwarf.BaseRegister && piece.Register == 7:
+			name = fmt.Sprintf("framebase%+d", piece.Offset+8)
+		}
+		if name == "" {
+			return "", false
+		}
+		if len(names) == 0 || names[len(names)-1] != name {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " | "), true
+}
+
+// ValueString describes a value computed by a DWARF expression. It returns
+// true if the value is a constant.
+func ValueString(loc *dwarf.Location) (string, bool) {
+	if loc == nil || len(loc.Pieces) != 1 {
+		return "", false
+	}
+	piece := loc.Pieces[0]
+	offset := ""
+	if piece.Offset != 0 {
+		offset = fmt.Sprintf("%+d", piece.Offset)
+	}
+
+	switch piece.Kind {
+	case dwarf.PieceRegister:
+		return DwarfRegisters[piece.Register], false
+	case dwarf.PieceImplicitValue:
+		return fmt.Sprintf("0x%x", piece.Value), true
+
+	// The expression computes a value (and not where one is)
+	case dwarf.PieceMemory, dwarf.PieceValue:
+		switch piece.Base {
+		case dwarf.BaseNone:
+			return fmt.Sprintf("%d", piece.Offset), true
+		case dwarf.BaseRegister:
+			return DwarfRegisters[piece.Register] + offset, false
+		case dwarf.BaseEntryValue:
+			return "entry(" + DwarfRegisters[piece.Register] + ")" + offset, false
+		case dwarf.BaseFrameBase:
+			return "framebase" + offset, false
+		}
+	}
+	return "", false
+}
+
+// sseArgumentSize returns the size of the floating point parameter an
+// argument in an SSE register is for (4 for a float, 8 for a double), or 0 if
+// it is unknown. Without a parameter from the compiler, %xmmN is the Nth float
+// or double parameter of the function called, and after those, a variadic
+// function gets doubles (floats are promoted). For an indirect call, only the
+// type of a typed constant (DW_OP_const_type) might be known.
+func sseArgumentSize(cs *file.CallSite, param *dwarf.Entry, value *dwarf.Location, location string) int64 {
+	if t := cs.ArgumentType(param); t != nil {
+		return floatSize(t)
+	}
+	function := cs.CalleeType()
+	var register int
+	if function == nil || strings.Contains(location, " ") {
+		return constantSize(cs, value)
+	}
+	if _, err := fmt.Sscanf(location, "%%xmm%d", &register); err != nil {
+		return 0
+	}
+
+	floats := 0
+	for _, t := range function.ParamType {
+		if _, ok := t.(*dwarf.DotDotDotType); ok {
+			return 8
+		}
+		if size := floatSize(t); size > 0 {
+			if floats == register {
+				return size
+			}
+			floats++
+			continue
+		}
+
+		// A structure or complex number can be passed in SSE registers too
+		switch unqualified(t).(type) {
+		case *dwarf.StructType, *dwarf.ComplexType, *dwarf.ArrayType:
+			return 0
+		}
+	}
+	return 0
+}
+
+// constantSize returns the size of the type of a typed constant, if it is a
+// float or double
+func constantSize(cs *file.CallSite, value *dwarf.Location) int64 {
+	if value == nil || len(value.Pieces) != 1 || value.Pieces[0].Type == 0 {
+		return 0
+	}
+	t, err := cs.Data.Type(value.Pieces[0].Type)
+	if err != nil {
+		return 0
+	}
+	return floatSize(t)
+}
+
+// floatSize returns the size of a float or double type, or 0
+func floatSize(t dwarf.Type) int64 {
+	if float, ok := unqualified(t).(*dwarf.FloatType); ok && (float.ByteSize == 4 || float.ByteSize == 8) {
+		return float.ByteSize
+	}
+	return 0
+}
+
+// unqualified removes typedefs and qualifiers from a type
+func unqualified(t dwarf.Type) dwarf.Type {
+	for {
+		switch convert := t.(type) {
+		case *dwarf.TypedefType:
+			t = convert.Type
+		case *dwarf.QualType:
+			t = convert.Type
+		default:
+			return t
+		}
+	}
+}
+
+// floatString converts the bits of a constant passed in an SSE register to a
+// float (size 4) or double (size 8). The bits are a constant, or the bytes of
+// an implicit value (DW_OP_implicit_value, little endian).
+func floatString(loc *dwarf.Location, size int64) (string, bool) {
+	if loc == nil || len(loc.Pieces) != 1 || (size != 4 && size != 8) {
+		return "", false
+	}
+	piece := loc.Pieces[0]
+	var bits uint64
+	switch {
+	case piece.Kind == dwarf.PieceImplicitValue:
+		if int64(len(piece.Value)) < size {
+			return "", false
+		}
+		for i := size - 1; i >= 0; i-- {
+			bits = bits<<8 | uint64(piece.Value[i])
+		}
+	case (piece.Kind == dwarf.PieceMemory || piece.Kind == dwarf.PieceValue) && piece.Base == dwarf.BaseNone:
+		bits = uint64(piece.Offset)
+	default:
+		return "", false
+	}
+	if size == 4 {
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(bits))), 'g', -1, 32), true
+	}
+	return strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64), true
+}
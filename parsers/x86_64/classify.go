@@ -56,6 +56,15 @@ func ClassifyPointer(ptrCount *int64) Classification {
 	return Classification{Lo: INTEGER, Hi: NO_CLASS, Name: "Pointer", PointerIndirections: (*ptrCount)}
 }
 
+// ClassifyMemberPointer classifies a pointer to member, where a pointer to
+// member function is a function pointer and an adjustment for this
+func ClassifyMemberPointer(t *dwarf.PtrToMemberType) Classification {
+	if t.IsFunction() {
+		return Classification{Lo: INTEGER, Hi: INTEGER, Name: "MemberPointer"}
+	}
+	return Classification{Lo: INTEGER, Hi: NO_CLASS, Name: "MemberPointer"}
+}
+
 // ClassifyArray will classify an array
 func ClassifyArray(t *dwarf.ArrayType, c *file.Component, ptrCount *int64) Classification {
 
@@ -96,6 +105,118 @@ func ClassifyStruct(t *dwarf.StructType, c *file.Component, ptrCount *int64) Cla
 	return Classification{Lo: lo, Hi: hi, Name: kind}
 }
 
+// ClassifyTaggedUnion classifies a discriminated union (e.g., a Rust enum)
+// by each eightbyte, like a union of the discriminant and each variant
+func ClassifyTaggedUnion(t *dwarf.StructType, ptrCount *int64) Classification {
+	size := t.CommonType.Size()
+	if size > 16 {
+		return Classification{Lo: MEMORY, Hi: NO_CLASS, Name: "TaggedUnion"}
+	}
+	classes := []RegisterClass{NO_CLASS, NO_CLASS}
+	if t.Variants.Discriminant != nil {
+		classifyEightbytes(t.Variants.Discriminant.Type, t.Variants.Discriminant.ByteOffset, classes)
+	}
+	for _, variant := range t.Variants.Variants {
+		for _, field := range variant.Field {
+			classifyEightbytes(field.Type, field.ByteOffset, classes)
+		}
+	}
+	lo, hi := classes[0], classes[1]
+	postMerge(&lo, &hi, size)
+	return Classification{Lo: lo, Hi: hi, Name: "TaggedUnion"}
+}
+
+// classifyEightbytes merges the class of each scalar in a type into the
+// eightbyte it starts in (the classes for offsets 0 and 8)
+func classifyEightbytes(t dwarf.Type, offset int64, classes []RegisterClass) {
+	switch convert := t.(type) {
+	case *dwarf.StructType:
+		for _, field := range convert.Field {
+			classifyEightbytes(field.Type, offset+field.ByteOffset, classes)
+		}
+		if convert.Variants != nil {
+			if convert.Variants.Discriminant != nil {
+				classifyEightbytes(convert.Variants.Discriminant.Type, offset+convert.Variants.Discriminant.ByteOffset, classes)
+			}
+			for _, variant := range convert.Variants.Variants {
+				for _, field := range variant.Field {
+					classifyEightbytes(field.Type, offset+field.ByteOffset, classes)
+				}
+			}
+		}
+		return
+	case *dwarf.TypedefType:
+		classifyEightbytes(convert.Type, offset, classes)
+		return
+	case *dwarf.QualType:
+		classifyEightbytes(convert.Type, offset, classes)
+		return
+	case *dwarf.PtrToMemberType:
+		classifyEightbytes(&dwarf.PtrType{}, offset, classes)
+		if convert.IsFunction() {
+			classifyEightbytes(&dwarf.PtrType{}, offset+8, classes)
+		}
+		return
+	case *dwarf.ArrayType:
+		size := convert.Type.Size()
+		for i := int64(0); i < convert.Count && size > 0; i++ {
+			classifyEightbytes(convert.Type, offset+i*size, classes)
+		}
+		return
+	}
+
+	index := offset / 8
+	if index < 0 || index >= int64(len(classes)) {
+		return
+	}
+
+	// A complex number is like a structure of the real and imaginary parts
+	if complex, ok := t.(*dwarf.ComplexType); ok {
+		part := &dwarf.FloatType{BasicType: dwarf.BasicType{CommonType: dwarf.CommonType{ByteSize: complex.Size() / 2}}}
+		classifyEightbytes(part, offset, classes)
+		classifyEightbytes(part, offset+part.Size(), classes)
+		return
+	}
+
+	// A field that isn't aligned (in a packed structure) is passed in memory
+	size := t.Size()
+	if size > 0 && size <= 8 && offset%size != 0 {
+		classes[index] = MEMORY
+		return
+	}
+
+	class := INTEGER
+	_, float := t.(*dwarf.FloatType)
+	switch {
+	case float && size <= 8:
+		class = SSE
+
+	// A long double is X87 in the first eightbyte and X87UP in the second
+	case float && size == 16 && offset%16 == 0 && index+1 < int64(len(classes)):
+		classes[index] = merge(classes[index], X87)
+		classes[index+1] = merge(classes[index+1], X87UP)
+		return
+	case float:
+		class = MEMORY
+	}
+	classes[index] = merge(classes[index], class)
+}
+
+// ClassifyAggregate classifies a structure, union or array by each eightbyte,
+// e.g., to find if it is returned in memory. An aggregate larger than two
+// eightbytes is passed in memory.
+func ClassifyAggregate(t dwarf.Type, name string) Classification {
+	size := t.Size()
+	if size > 16 {
+		return Classification{Lo: MEMORY, Hi: NO_CLASS, Name: name}
+	}
+	classes := []RegisterClass{NO_CLASS, NO_CLASS}
+	classifyEightbytes(t, 0, classes)
+	lo, hi := classes[0], classes[1]
+	postMerge(&lo, &hi, size)
+	return Classification{Lo: lo, Hi: hi, Name: name}
+}
+
 // Merge lo and hi, Page 21 (bottom) AMD64 ABI - method to come up with final classification based on two
 func merge(originalReg RegisterClass, newReg RegisterClass) RegisterClass {
 
@@ -195,6 +316,31 @@ func ClassifyType(c *file.Component, ptrCount *int64) Classification {
 		convert := c.RawType.(*dwarf.EnumType)
 		return ClassifyEnum(convert, c, ptrCount)
 
+	// References are passed as pointers
+	case "Reference":
+		return ClassifyPointer(ptrCount)
+
+	case "MemberPointer":
+		convert := c.RawType.(*dwarf.PtrToMemberType)
+		return ClassifyMemberPointer(convert)
+
+	// A typedef is classified like the type it names
+	case "Typedef":
+		_, underlying := file.TypedefChain(c.RawType.(*dwarf.TypedefType))
+		if underlying == nil {
+			return Classification{Lo: NO_CLASS, Hi: NO_CLASS, Name: "Unknown"}
+		}
+		resolved := file.Component{Name: c.Name, Class: file.GetStringType(underlying), Size: underlying.Size(),
+			RawType: underlying.Common().Original}
+		return ClassifyType(&resolved, ptrCount)
+
+	// A Fortran character type is like an array of char
+	case "String":
+		if c.Size > 0 && c.Size <= 8 {
+			return Classification{Lo: INTEGER, Hi: NO_CLASS, Name: "String"}
+		}
+		return Classification{Lo: MEMORY, Hi: NO_CLASS, Name: "String"}
+
 	// Smeagle c++ most similar function is called classify_scalar
 	case "Basic", "Uint", "Int", "Float", "Char", "Uchar", "Complex", "Bool", "Unspecified", "Address":
 		return ClassifyBasic(c, ptrCount)
@@ -204,7 +350,7 @@ func ClassifyType(c *file.Component, ptrCount *int64) Classification {
 		convert := c.RawType.(*dwarf.StructType)
 		return ClassifyStruct(convert, c, ptrCount)
 	default:
-		log.Fatalf("Unnacounted for class in classifyType", c.Class)
+		log.Fatalf("Unnacounted for class in classifyType %s", c.Class)
 	}
 
 	return Classification{Lo: NO_CLASS, Hi: NO_CLASS, Name: "Unknown"}
@@ -261,7 +407,7 @@ func ClassifyBasic(c *file.Component, ptrCount *int64) Classification {
 
 	// TODO this should be Fatalf when this code is done
 	default:
-		log.Printf("Scalar classification type not accounted for:", c.Class)
+		log.Printf("Scalar classification type not accounted for: %s", c.Class)
 	}
 	return Classification{}
 }
@@ -28,15 +28,30 @@ func ParseFunction(f *file.File, symbol file.Symbol, entry *file.DwarfEntry, dis
 	// Get the direction for the function
 	direction := GetDirection(symbol.GetName(), isCallSite)
 
-	// A return value will be included here with name "return"
-	for _, c := range (*entry).GetComponents() {
+	// A return value will be included here with name "return" (it is last, but
+	// the address of one returned in memory is passed first)
+	comps := file.HiddenLengths(*entry, symbol.GetName(), (*entry).GetComponents())
+	returnAllocator := NewReturnAllocator()
+	if len(comps) > 0 && comps[len(comps)-1].Name == "return" {
+		returnAllocator = ReturnAllocator(comps[len(comps)-1], allocator)
+	}
+	for _, c := range comps {
 
 		indirections := int64(0)
 
+		// The this pointer of a method is passed like any other pointer
+		if c.Implicit {
+			c = c.Unqualified()
+		}
+
 		// Parse the parameter!
-		param := ParseParameter(c, data, symbol, &indirections, &seen, allocator, isCallSite)
+		a := allocator
+		if c.Name == "return" {
+			a = returnAllocator
+		}
+		param := ParseParameter(c, data, symbol, &indirections, &seen, a, isCallSite)
 		if param != nil {
-			params = append(params, param)
+			params = append(params, descriptor.WithDwarfLocation(param, file.DescribeLocation(c.Location, DwarfRegisters)))
 		}
 	}
 	return descriptor.FunctionDescription{Parameters: params, Name: symbol.GetName(), Type: "Function", Direction: direction}
@@ -50,39 +65,76 @@ func ParseParameter(c file.Component, d *dwarf.Data, symbol file.Symbol, indirec
 	switch c.Class {
 	case "Pointer":
 		return ParsePointerType(c, d, symbol, indirections, seen, a, isCallSite)
+	case "Reference":
+		return ParseReferenceType(c, d, symbol, indirections, seen, a, isCallSite)
+	case "MemberPointer":
+		return ParseMemberPointerType(c, a, isCallSite)
 	case "Qualified":
 		return ParseQualifiedType(c, d, symbol, indirections, seen, a, isCallSite)
 	case "Basic", "Uint", "Int", "Float", "Char", "Uchar", "Complex", "Bool", "Unspecified", "Address":
 		return ParseBasicType(c, d, symbol, indirections, seen, a, isCallSite)
 	case "Enum":
 		return ParseEnumType(c, symbol, indirections, a, isCallSite)
+	// A typedef is resolved through its own entry, since a name can be defined
+	// differently in each compile unit
 	case "Typedef":
-		convert := (*d).StructCache[c.Name]
-		if convert != nil {
-			return ParseStructure(convert, d, symbol, indirections, seen, a, isCallSite)
-		}
-		return ParseTypedef(c, symbol, indirections, seen, isCallSite)
+		return ParseTypedef(c, d, symbol, indirections, seen, a, isCallSite)
 	case "Structure":
 		convert := c.RawType.(*dwarf.StructType)
+		if convert.Variants != nil {
+			union := ParseTaggedUnion(convert, d, symbol, indirections, seen, a, isCallSite)
+			union.Name = c.Name
+			return union
+		}
 		return ParseStructure(convert, d, symbol, indirections, seen, a, isCallSite)
 	case "Array":
 		return ParseArray(c, d, symbol, indirections, seen, a, isCallSite)
+	case "String":
+		return ParseStringType(c, indirections, a, isCallSite)
 
-	// A nested function here appears to be anonymous (e.g., { Function -1   func(*char) void})
-	case "", "Undefined", "Function":
+	// A function type is passed as a pointer to the function (e.g., { Function -1   func(*char) void})
+	case "Function":
+		return ParseFunctionPointer(c, c.RawType.(*dwarf.FuncType), d, seen, a, isCallSite)
+	case "", "Undefined":
 		return nil
 	default:
-		log.Fatalf("Unparsed parameter class", c.Class)
+		log.Fatalf("Unparsed parameter class %s", c.Class)
 	}
 	return nil
 }
 
-// ParseTypeDef parses a type definition
-func ParseTypedef(c file.Component, symbol file.Symbol, indirections *int64, seen *map[string]file.Component, isCallSite bool) descriptor.Parameter {
+// ParseTypeDef parses a type definition. It is passed like the type it names,
+// so the chain of typedefs is resolved to that type, and their names are kept.
+func ParseTypedef(c file.Component, d *dwarf.Data, symbol file.Symbol, indirections *int64, seen *map[string]file.Component,
+	a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
 	convert := c.RawType.(*dwarf.TypedefType)
-	direction := GetDirection(convert.Name, isCallSite)
-	return descriptor.BasicParameter{Name: convert.Name, Size: convert.CommonType.Size(), Type: convert.Type.Common().Name,
-		Direction: direction, Class: "TypeDef"}
+	chain, underlying := file.TypedefChain(convert)
+
+	class := "Undefined"
+	if underlying != nil {
+		class = file.GetStringType(underlying)
+	}
+	switch class {
+	case "Undefined", "Unknown":
+		direction := GetDirection(convert.Name, isCallSite)
+		return descriptor.BasicParameter{Name: convert.Name, Size: convert.CommonType.Size(), Type: convert.Type.Common().Name,
+			Direction: direction, Class: "TypeDef", Annotations: descriptor.Annotations{TypedefChain: chain}}
+	}
+
+	comp := file.Component{Name: c.Name, Class: class, Size: underlying.Size(), Type: underlying.Common().Name,
+		Framebase: c.Framebase, VarParam: c.VarParam, Implicit: c.Implicit, Location: c.Location,
+		RawType: underlying.Common().Original}
+	param := ParseParameter(comp, d, symbol, indirections, seen, a, isCallSite)
+
+	// Structures don't know the name of the parameter they are
+	if structure, ok := param.(descriptor.StructureParameter); ok {
+		structure.Name = c.Name
+		param = structure
+	}
+	if param == nil {
+		return nil
+	}
+	return descriptor.WithTypedefChain(param, chain)
 }
 
 // ParseEnumType parses an enum type
@@ -132,6 +184,11 @@ func ParsePointerType(c file.Component, d *dwarf.Data, symbol file.Symbol, indir
 	// Convert Original back to Pointer Type to get underlying type
 	convert := c.RawType.(*dwarf.PtrType)
 
+	// A pointer to a function is described with the signature of the function
+	if function, ok := convert.Type.Common().Original.(*dwarf.FuncType); ok {
+		return ParseFunctionPointer(c, function, d, seen, a, isCallSite)
+	}
+
 	// Default will return nil (no underlying type to continue parsing)
 	underlyingType := ParseParameter(file.Component{}, d, nil, indirections, seen, a, isCallSite)
 
@@ -144,9 +201,9 @@ func ParsePointerType(c file.Component, d *dwarf.Data, symbol file.Symbol, indir
 		// If we've hit another pointer, this is an indirection
 		(*indirections) += 1
 
-		// Mark as seen, and parse the underlying type
+		// Mark as seen, and parse the underlying type (it is not passed in registers)
 		(*seen)[convert.Type.Common().Name] = comp
-		underlyingType = ParseParameter(comp, d, nil, indirections, seen, a, isCallSite)
+		underlyingType = ParseParameter(comp, d, nil, indirections, seen, NewRegisterAllocator(), isCallSite)
 	}
 
 	// Default direction for a library symbol is import
@@ -163,6 +220,81 @@ func ParsePointerType(c file.Component, d *dwarf.Data, symbol file.Symbol, indir
 		Size: c.Size, Direction: direction, UnderlyingType: underlyingType, Indirections: (*indirections)}
 }
 
+// ParseFunctionPointer parses a pointer to a function (e.g., a callback). The
+// parameters and return value of the function are described with their own
+// allocator, as the library will pass them when it calls the function, so
+// their direction is inverted.
+func ParseFunctionPointer(c file.Component, function *dwarf.FuncType, d *dwarf.Data, seen *map[string]file.Component,
+	a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
+
+	callback := descriptor.FunctionPointerParameter{Name: c.Name, Type: c.Type, Class: "FunctionPointer",
+		Size: 8, Direction: GetDirection(c.Name, isCallSite)}
+	if callback.Type == "" {
+		callback.Type = function.String()
+	}
+	ptrClass := ClassifyPointer(new(int64))
+	callback.Location = a.GetRegisterString(ptrClass.Lo, ptrClass.Hi, 8, "Pointer")
+
+	allocator := NewRegisterAllocator()
+	if function.ReturnType != nil {
+		returnType := function.ReturnType
+		comp := file.Component{Name: "return", Class: file.GetStringType(returnType), Size: returnType.Size(),
+			Type: returnType.Common().Name, RawType: returnType.Common().Original}
+		indirections := int64(0)
+		callback.Return = ParseParameter(comp, d, nil, &indirections, seen, ReturnAllocator(comp, allocator), !isCallSite)
+
+		// Structures don't know they are a return value
+		if result, ok := callback.Return.(descriptor.StructureParameter); ok {
+			result.Direction = GetDirection(comp.Name, !isCallSite)
+			callback.Return = result
+		}
+	}
+
+	for _, paramType := range function.ParamType {
+		if _, ok := paramType.(*dwarf.DotDotDotType); ok {
+			callback.Variadic = true
+			continue
+		}
+		comp := file.Component{Class: file.GetStringType(paramType), Size: paramType.Size(),
+			Type: paramType.Common().Name, RawType: paramType.Common().Original}
+		indirections := int64(0)
+		if param := ParseParameter(comp, d, nil, &indirections, seen, allocator, !isCallSite); param != nil {
+			callback.Parameters = append(callback.Parameters, param)
+		}
+	}
+	return callback
+}
+
+// ParseReferenceType parses a C++ reference, which is passed like a pointer
+func ParseReferenceType(c file.Component, d *dwarf.Data, symbol file.Symbol, indirections *int64, seen *map[string]file.Component,
+	a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
+
+	convert := c.RawType.(*dwarf.RefType)
+
+	// The referenced type is not passed in registers
+	var underlyingType descriptor.Parameter
+	if _, ok := (*seen)[convert.Type.Common().Name]; !ok {
+		comp := file.Component{Name: convert.Type.Common().Name, Class: file.GetStringType(convert.Type),
+			Size: convert.Type.Size(), RawType: convert.Type.Common().Original}
+		(*seen)[convert.Type.Common().Name] = comp
+		underlyingType = ParseParameter(comp, d, nil, indirections, seen, NewRegisterAllocator(), isCallSite)
+	}
+
+	refClass := ClassifyPointer(indirections)
+	loc := a.GetRegisterString(refClass.Lo, refClass.Hi, c.Size, c.Class)
+	return descriptor.ReferenceParameter{Name: c.Name, Type: convert.String(), Class: c.Class, Location: loc, Size: c.Size,
+		Direction: GetDirection(c.Name, isCallSite), Rvalue: convert.Rvalue, UnderlyingType: underlyingType}
+}
+
+// ParseMemberPointerType parses a C++ pointer to member
+func ParseMemberPointerType(c file.Component, a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
+	convert := c.RawType.(*dwarf.PtrToMemberType)
+	cls := ClassifyMemberPointer(convert)
+	return descriptor.MemberPointerParameter{Name: c.Name, Type: convert.String(), Class: c.Class,
+		Location: a.GetAggregateString(cls.Lo, cls.Hi, convert.Size()), Size: convert.Size(),
+		Direction: GetDirection(c.Name, isCallSite), ContainingType: convert.Class.String(), Function: convert.IsFunction()}
+}
+
 /* ParseArray parses an array type
 
 1. For formal function parameters, arrays are pointers and have class "Pointer"
@@ -196,14 +328,55 @@ func ParseArray(c file.Component, d *dwarf.Data, symbol file.Symbol, indirection
 	arrayClass := ClassifyArray(convert, &seenComponent, indirections)
 	loc := a.GetRegisterString(arrayClass.Lo, arrayClass.Hi, seenComponent.Size, seenComponent.Class)
 	direction := GetDirection(convert.CommonType.Name, isCallSite)
-	return descriptor.ArrayParameter{Length: convert.Count, Name: convert.CommonType.Name, Type: convert.Type.String(),
+	array := descriptor.ArrayParameter{Length: convert.Count, Name: convert.CommonType.Name, Type: convert.Type.String(),
 		Size: convert.Count * seenComponent.Size, Class: "Array", ItemType: underlyingType, Location: loc, Direction: direction}
+
+	// The shape of some arrays (e.g., Fortran assumed shape) is only known at run time
+	if convert.Dynamic() {
+		array.Dynamic = true
+		array.Size = 0
+		array.Length = 0
+		for _, bound := range convert.Bounds {
+			array.Bounds = append(array.Bounds, descriptor.ArrayBound{Lower: file.DescribeBound(bound.Lower),
+				Upper: file.DescribeBound(bound.Upper), Count: file.DescribeBound(bound.Count),
+				Stride: file.DescribeBound(bound.Stride)})
+		}
+		if convert.DataLocation != nil {
+			array.DataLocation = file.DescribeBound(dwarf.Bound{Expr: convert.DataLocation})
+		}
+		array.Descriptor = file.ArrayDescriptor(convert)
+	}
+	return array
+}
+
+// ParseStringType parses a Fortran character type, which is passed by value
+// only for bind(C) procedures (e.g., character(len=1), value)
+func ParseStringType(c file.Component, indirections *int64, a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
+	convert := c.RawType.(*dwarf.StringType)
+	stringClass := ClassifyType(&c, indirections)
+	loc := a.GetRegisterString(stringClass.Lo, stringClass.Hi, c.Size, c.Class)
+	direction := GetDirection(c.Name, isCallSite)
+	return descriptor.BasicParameter{Name: c.Name, Type: convert.String(), Class: "String", Size: c.Size,
+		Location: loc, Direction: direction}
 }
 
 // ParseStructure parses a structure type
 func ParseStructure(convert *dwarf.StructType, d *dwarf.Data, symbol file.Symbol, indirections *int64, seen *map[string]file.Component,
 	a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
 
+	// A declared type can be defined in another compile unit (or library)
+	opaque := false
+	if convert.Incomplete {
+		if definition := d.Definition(convert); definition != nil {
+			convert = definition
+		} else {
+			opaque = true
+		}
+	}
+	if convert.Variants != nil {
+		return ParseTaggedUnion(convert, d, symbol, indirections, seen, a, isCallSite)
+	}
+
 	fields := []descriptor.Parameter{}
 	for _, field := range convert.Field {
 		c := file.Component{Name: field.Name, Class: file.GetStringType(field.Type),
@@ -218,8 +391,127 @@ func ParseStructure(convert *dwarf.StructType, d *dwarf.Data, symbol file.Symbol
 	// Get the location ?
 	// structClass := ClassifyStruct(convert, &c, indirections)
 	// loc := a.GetRegisterString(structClass.Lo, structClass.Hi, c.Size, c.Class)
-	return descriptor.StructureParameter{Fields: fields, Class: strings.Title(convert.Kind), Type: convert.StructName,
-		Size: convert.CommonType.Size(), Direction: direction}
+	structure := descriptor.StructureParameter{Fields: fields, Class: strings.Title(convert.Kind), Type: convert.StructName,
+		Size: convert.CommonType.Size(), Direction: direction, Bases: ParseBases(convert), Vtable: ParseVtable(convert),
+		Opaque: opaque}
+	if len(convert.TemplateParams) > 0 {
+		structure.Template = file.TemplateName(convert.StructName)
+		structure.TemplateArguments = file.DescribeTemplateParams(convert.TemplateParams)
+	}
+	return structure
+}
+
+// ParseTaggedUnion parses a discriminated union (a struct with a variant part)
+func ParseTaggedUnion(convert *dwarf.StructType, d *dwarf.Data, symbol file.Symbol, indirections *int64, seen *map[string]file.Component,
+	a *RegisterAllocator, isCallSite bool) descriptor.TaggedUnionParameter {
+
+	union := descriptor.TaggedUnionParameter{Type: convert.StructName, Class: "TaggedUnion", Size: convert.CommonType.Size(),
+		Direction: GetDirection("", isCallSite)}
+
+	discriminant := convert.Variants.Discriminant
+	if discriminant != nil {
+		union.Discriminant = &descriptor.Discriminant{Type: discriminant.Type.String(), Offset: discriminant.ByteOffset,
+			Size: discriminant.Type.Size()}
+	}
+
+	// The fields of a variant are not passed in registers on their own
+	niche := false
+	for _, variant := range convert.Variants.Variants {
+		described := descriptor.Variant{Default: len(variant.Values) == 0}
+		for _, value := range variant.Values {
+			if value.Low == value.High {
+				described.Values = append(described.Values, value.Low)
+			} else {
+				described.Ranges = append(described.Ranges, [2]int64{value.Low, value.High})
+			}
+		}
+		for _, field := range variant.Field {
+			if described.Name == "" {
+				described.Name = field.Name
+			}
+			if discriminant != nil && overlaps(field, discriminant) {
+				niche = true
+			}
+			c := file.Component{Name: field.Name, Class: file.GetStringType(field.Type),
+				Size: field.Type.Size(), RawType: field.Type.Common().Original}
+			if parsed := ParseParameter(c, d, nil, indirections, seen, NewRegisterAllocator(), isCallSite); parsed != nil {
+				described.Fields = append(described.Fields, parsed)
+			}
+		}
+		union.Variants = append(union.Variants, described)
+	}
+
+	// Values that select a variant, but are stored in the data of another
+	if niche {
+		for _, variant := range union.Variants {
+			union.NicheValues = append(union.NicheValues, variant.Values...)
+		}
+	}
+
+	if len(convert.TemplateParams) > 0 {
+		union.Template = file.TemplateName(convert.StructName)
+		union.TemplateArguments = file.DescribeTemplateParams(convert.TemplateParams)
+	}
+
+	cls := ClassifyTaggedUnion(convert, indirections)
+	union.Location = a.GetAggregateString(cls.Lo, cls.Hi, union.Size)
+	return union
+}
+
+// overlaps determines if the data of a variant's field includes the discriminant
+func overlaps(field *dwarf.StructField, discriminant *dwarf.StructField) bool {
+	ranges := [][2]int64{}
+	collectScalars(field.Type, field.ByteOffset, &ranges)
+	start := discriminant.ByteOffset
+	end := start + discriminant.Type.Size()
+	for _, r := range ranges {
+		if r[0] < end && start < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// collectScalars lists the byte ranges of the scalars in a type
+func collectScalars(t dwarf.Type, offset int64, ranges *[][2]int64) {
+	switch convert := t.(type) {
+	case *dwarf.StructType:
+		for _, field := range convert.Field {
+			collectScalars(field.Type, offset+field.ByteOffset, ranges)
+		}
+		return
+	case *dwarf.TypedefType:
+		collectScalars(convert.Type, offset, ranges)
+		return
+	}
+	if t.Size() > 0 {
+		*ranges = append(*ranges, [2]int64{offset, offset + t.Size()})
+	}
+}
+
+// ParseBases describes the base classes of a C++ class
+func ParseBases(convert *dwarf.StructType) []descriptor.BaseClass {
+	bases := []descriptor.BaseClass{}
+	for _, base := range convert.Bases {
+		bases = append(bases, descriptor.BaseClass{Type: base.Type.String(), Offset: base.ByteOffset,
+			Virtual: base.Virtual, Access: base.Access})
+	}
+	return bases
+}
+
+// ParseVtable describes the vtable of a polymorphic class (slot to method)
+func ParseVtable(convert *dwarf.StructType) []descriptor.VtableSlot {
+	slots := []descriptor.VtableSlot{}
+	for i, method := range convert.Vtable() {
+		slot := descriptor.VtableSlot{Slot: int64(i)}
+		if method != nil {
+			slot.Name = method.Name
+			slot.LinkageName = method.LinkageName
+			slot.Deleting = method.Deleting
+		}
+		slots = append(slots, slot)
+	}
+	return slots
 }
 
 // ParseQualified parses a qualified type (a size and type)
@@ -233,8 +525,15 @@ func ParseQualifiedType(c file.Component, d *dwarf.Data, symbol file.Symbol, ind
 	case *dwarf.QualType:
 		convert := c.RawType.(*dwarf.QualType)
 		direction := GetDirection("", isCallSite)
-		return descriptor.QualifiedParameter{Size: convert.Type.Size(), Type: convert.Type.String(), Class: "Qual",
-			Direction: direction}
+
+		// const, volatile, restrict and _Atomic values are passed like the type they qualify
+		location := ""
+		count := *indirections
+		if underlying := ParseParameter(c.Unqualified(), d, symbol, &count, &map[string]file.Component{}, a, isCallSite); underlying != nil {
+			location = underlying.GetLocation()
+		}
+		return descriptor.QualifiedParameter{Name: c.Name, Size: convert.Type.Size(), Type: convert.Type.String(), Class: "Qual",
+			Direction: direction, Location: location}
 	}
 	return descriptor.QualifiedParameter{}
 }
@@ -243,7 +542,7 @@ func ParseQualifiedType(c file.Component, d *dwarf.Data, symbol file.Symbol, ind
 func ParseBasicType(c file.Component, d *dwarf.Data, symbol file.Symbol, indirections *int64, seen *map[string]file.Component,
 	a *RegisterAllocator, isCallSite bool) descriptor.Parameter {
 
-	direction := GetDirection("", isCallSite)
+	direction := GetDirection(c.Name, isCallSite)
 	cls := ClassifyType(&c, indirections)
 	loc := a.GetRegisterString(cls.Lo, cls.Hi, c.Size, c.Class)
 
@@ -291,7 +590,7 @@ func ParseBasicType(c file.Component, d *dwarf.Data, symbol file.Symbol, indirec
 		return descriptor.BasicParameter{Size: convert.CommonType.Size(), Type: convert.CommonType.Name, Direction: direction,
 			Name: c.Name, Class: c.Class, Location: loc}
 	default:
-		log.Fatalf("Type not accounted for:", reflect.TypeOf(c.RawType))
+		log.Fatalf("Type not accounted for: %v", reflect.TypeOf(c.RawType))
 	}
 	return descriptor.BasicParameter{}
 }
@@ -305,7 +604,8 @@ func ParseVariable(f *file.File, symbol file.Symbol, entry *file.DwarfEntry, isC
 	// A variable will only have one component for itself
 	for _, v := range (*entry).GetComponents() {
 		direction := GetDirection(v.Name, isCallSite)
-		variable = descriptor.VariableDescription{Name: v.Name, Type: v.Type, Size: v.Size, Direction: direction}
+		variable = descriptor.VariableDescription{Name: v.Name, Type: v.Type, Size: v.Size, Direction: direction,
+			DwarfLocation: file.DescribeLocation(v.Location, DwarfRegisters)}
 	}
 	return variable
 }
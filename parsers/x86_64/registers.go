@@ -0,0 +1,98 @@
+package x86_64
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
+)
+
+// DwarfRegisters maps DWARF register numbers to names (System V psABI, Figure 3.36)
+var DwarfRegisters = map[int]string{
+	0: "%rax", 1: "%rdx", 2: "%rcx", 3: "%rbx", 4: "%rsi", 5: "%rdi", 6: "%rbp", 7: "%rsp",
+	8: "%r8", 9: "%r9", 10: "%r10", 11: "%r11", 12: "%r12", 13: "%r13", 14: "%r14", 15: "%r15",
+	16: "%rip",
+}
+
+func init() {
+	for i := 0; i < 16; i++ {
+		DwarfRegisters[17+i] = fmt.Sprintf("%%xmm%d", i)
+	}
+	for i := 0; i < 8; i++ {
+		DwarfRegisters[33+i] = fmt.Sprintf("%%st%d", i)
+	}
+}
+
+// EntryLocationString converts a DWARF location that is valid at function entry
+// to the syntax we predict: registers are "%rdi" and stack slots are relative to
+// the stack pointer at entry ("framebase+8" is the first stack argument, right
+// after the return address). frameBase is the function's DW_AT_frame_base.
+// It returns false if the location cannot be expressed that way.
+func EntryLocationString(loc *dwarf.Location, frameBase *dwarf.Location) (string, bool) {
+	if loc == nil || len(loc.Pieces) == 0 {
+		return "", false
+	}
+	names := []string{}
+
+	// A value spread over consecutive stack pieces is reported by where it starts
+	var stackEnd int64 = -1
+	for _, piece := range loc.Pieces {
+		name := ""
+		switch piece.Kind {
+		case dwarf.PieceRegister:
+			name = DwarfRegisters[piece.Register]
+			stackEnd = -1
+
+		case dwarf.PieceMemory:
+			offset, ok := entryStackOffset(piece, frameBase)
+			if !ok {
+				return "", false
+			}
+			if offset == stackEnd {
+				stackEnd += piece.Size
+				continue
+			}
+			stackEnd = offset + piece.Size
+			name = fmt.Sprintf("framebase%+d", offset)
+		}
+		if name == "" {
+			return "", false
+		}
+
+		// Pieces of one register are reported once
+		if len(names) > 0 && names[len(names)-1] == name {
+			continue
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, " | "), true
+}
+
+// entryStackOffset returns the offset of a memory location from the entry stack pointer
+func entryStackOffset(piece dwarf.Piece, frameBase *dwarf.Location) (int64, bool) {
+	switch piece.Base {
+
+	// The stack pointer is only the frame base we expect at the first instruction
+	case dwarf.BaseRegister:
+		return piece.Offset, piece.Register == 7
+
+	// The CFA is the stack pointer before the call pushed the return address
+	case dwarf.BaseCFA:
+		return piece.Offset + 8, true
+
+	case dwarf.BaseFrameBase:
+		if frameBase == nil || len(frameBase.Pieces) != 1 {
+			return 0, false
+		}
+		base := frameBase.Pieces[0]
+		if base.Kind == dwarf.PieceRegister {
+			return piece.Offset, base.Register == 7
+		}
+		if base.Kind != dwarf.PieceMemory {
+			return 0, false
+		}
+		offset, ok := entryStackOffset(base, nil)
+		return offset + piece.Offset, ok
+	}
+	return 0, false
+}
@@ -199,6 +199,9 @@ const (
 	// http://www.dwarfstd.org/ShowIssue.php?issue=120604.1
 	formGnuRefAlt  format = 0x1f20
 	formGnuStrpAlt format = 0x1f21
+	// ADDED: extensions for split DWARF before DWARF 5 (-gsplit-dwarf)
+	formGnuAddrIndex format = 0x1f01
+	formGnuStrIndex  format = 0x1f02
 )
 
 //go:generate stringer -type Tag -trimprefix=Tag
@@ -372,6 +375,11 @@ const (
 	opConvert         = 0xA8
 	opReinterpret     = 0xA9
 	/* 0xE0-0xFF reserved for user-specific */
+	opGNUPushTLSAddress  = 0xE0
+	opGNUEntryValue      = 0xF3
+	opGNUImplicitPointer = 0xF2
+	opGNUConstType       = 0xF4 // ADDED: DW_OP_const_type before DWARF 5
+	opGNUParameterRef    = 0xFA
 )
 
 // Basic type encodings -- the value for AttrEncoding in a TagBaseType Entry.
@@ -449,6 +457,15 @@ const (
 	lleBaseAddress     = 0x06
 	lleStartEnd        = 0x07
 	lleStartLength     = 0x08
+	lleGNUViewPair     = 0x09 // GNU extension for location views
+)
+
+// ADDED: location list entries of split units before DWARF 5 (.debug_loc.dwo)
+const (
+	lleGNUEndOfList   = 0x00
+	lleGNUBaseAddress = 0x01
+	lleGNUStartEnd    = 0x02
+	lleGNUStartLength = 0x03
 )
 
 // Unit header unit type encodings.
@@ -473,3 +490,22 @@ const (
 	rleStartEnd     = 0x6
 	rleStartLength  = 0x7
 )
+
+// Source language codes -- the value for AttrLanguage in a TagCompileUnit Entry.
+// Added by @vsoch so parsers can pick a calling convention per unit.
+const (
+	LangC89         = 0x01
+	LangC           = 0x02
+	LangCPlusPlus   = 0x04
+	LangFortran77   = 0x07
+	LangFortran90   = 0x08
+	LangC99         = 0x0C
+	LangFortran95   = 0x0E
+	LangGo          = 0x16
+	LangCPlusPlus11 = 0x1A
+	LangRust        = 0x1C
+	LangC11         = 0x1D
+	LangCPlusPlus14 = 0x21
+	LangFortran03   = 0x22
+	LangFortran08   = 0x23
+)
@@ -164,7 +164,7 @@ func formToClass(form format, attr Attr, vers int, b *buf) Class {
 	case formIndirect:
 		return ClassUnknown
 
-	case formAddr, formAddrx, formAddrx1, formAddrx2, formAddrx3, formAddrx4:
+	case formAddr, formAddrx, formAddrx1, formAddrx2, formAddrx3, formAddrx4, formGnuAddrIndex:
 		return ClassAddress
 
 	case formDwarfBlock1, formDwarfBlock2, formDwarfBlock4, formDwarfBlock:
@@ -199,7 +199,7 @@ func formToClass(form format, attr Attr, vers int, b *buf) Class {
 	case formRefSig8:
 		return ClassReferenceSig
 
-	case formString, formStrp, formStrx, formStrpSup, formLineStrp, formStrx1, formStrx2, formStrx3, formStrx4:
+	case formString, formStrp, formStrx, formStrpSup, formLineStrp, formStrx1, formStrx2, formStrx3, formStrx4, formGnuStrIndex:
 		return ClassString
 
 	case formSecOffset:
@@ -424,6 +424,11 @@ func (b *buf) entry(cu *Entry, atab abbrevTable, ubase Offset, vers int) *Entry
 		Field:    make([]Field, len(a.field)),
 	}
 
+	// ADDED: a split unit reads strings and location lists from its own sections
+	if u, ok := b.format.(*unit); ok && u.split != nil {
+		b.dwarf = u.split.data
+	}
+
 	// If we are currently parsing the compilation unit,
 	// we can't evaluate Addrx or Strx until we've seen the
 	// relevant base entry.
@@ -435,6 +440,10 @@ func (b *buf) entry(cu *Entry, atab abbrevTable, ubase Offset, vers int) *Entry
 	var delay []delayed
 
 	resolveStrx := func(strBase, off uint64) string {
+		// ADDED: the string offsets of a split unit follow the section header
+		if u, ok := b.format.(*unit); ok && u.split != nil {
+			strBase = u.split.strOffsetsBase
+		}
 		off += strBase
 		if uint64(int(off)) != off {
 			b.error("DW_FORM_strx offset out of range")
@@ -480,10 +489,10 @@ func (b *buf) entry(cu *Entry, atab abbrevTable, ubase Offset, vers int) *Entry
 		// address
 		case formAddr:
 			val = b.addr()
-		case formAddrx, formAddrx1, formAddrx2, formAddrx3, formAddrx4:
+		case formAddrx, formAddrx1, formAddrx2, formAddrx3, formAddrx4, formGnuAddrIndex:
 			var off uint64
 			switch fmt {
-			case formAddrx:
+			case formAddrx, formGnuAddrIndex:
 				off = b.uint()
 			case formAddrx1:
 				off = uint64(b.uint8())
@@ -621,10 +630,10 @@ func (b *buf) entry(cu *Entry, atab abbrevTable, ubase Offset, vers int) *Entry
 				b.err = b1.err
 				return nil
 			}
-		case formStrx, formStrx1, formStrx2, formStrx3, formStrx4:
+		case formStrx, formStrx1, formStrx2, formStrx3, formStrx4, formGnuStrIndex:
 			var off uint64
 			switch fmt {
-			case formStrx:
+			case formStrx, formGnuStrIndex:
 				off = b.uint()
 			case formStrx1:
 				off = uint64(b.uint8())
@@ -974,6 +983,13 @@ func (d *Data) Ranges(e *Entry) ([][2]uint64, error) {
 		u = &d.unit[uidx]
 	}
 
+	// ADDED: a split unit has its own range lists (or, before DWARF 5, uses
+	// those of its skeleton unit after DW_AT_GNU_ranges_base)
+	var rangesBase int64
+	if u != nil && u.split != nil {
+		d, rangesBase = u.split.data, u.split.rangesBase
+	}
+
 	if u != nil && u.vers >= 5 && d.rngLists != nil {
 		// DWARF version 5 and later
 		field := e.AttrField(AttrRanges)
@@ -993,8 +1009,19 @@ func (d *Data) Ranges(e *Entry) ([][2]uint64, error) {
 			return d.dwarf5Ranges(u, cu, base, ranges, ret)
 
 		case ClassRngList:
-			// TODO: support DW_FORM_rnglistx
-			return ret, nil
+			idx, ok := field.Val.(uint64)
+			if !ok {
+				return ret, nil
+			}
+			cu, base, err := d.baseAddressForEntry(e)
+			if err != nil {
+				return nil, err
+			}
+			ranges, err := d.rngListOffset(u, cu, idx)
+			if err != nil {
+				return nil, err
+			}
+			return d.dwarf5Ranges(u, cu, base, ranges, ret)
 
 		default:
 			return ret, nil
@@ -1008,7 +1035,7 @@ func (d *Data) Ranges(e *Entry) ([][2]uint64, error) {
 		if err != nil {
 			return nil, err
 		}
-		return d.dwarf2Ranges(u, base, ranges, ret)
+		return d.dwarf2Ranges(u, base, ranges+rangesBase, ret)
 	}
 
 	return ret, nil
@@ -1042,6 +1069,10 @@ func (d *Data) baseAddressForEntry(e *Entry) (*Entry, uint64, error) {
 		return cu, cuLow, nil
 	}
 
+	// ADDED: the base address of a split unit is on its skeleton unit
+	if i := d.offsetToUnit(cu.Offset); i >= 0 && d.unit[i].split != nil {
+		return cu, d.unit[i].split.lowpc, nil
+	}
 	return cu, 0, nil
 }
 
@@ -1065,6 +1096,31 @@ func (d *Data) dwarf2Ranges(u *unit, base uint64, ranges int64, ret [][2]uint64)
 	return ret, nil
 }
 
+// ADDED: rngListOffset finds the offset of a list by index (DW_FORM_rnglistx).
+// The offsets table follows the unit's DW_AT_rnglists_base, and is relative to it.
+func (d *Data) rngListOffset(u *unit, cu *Entry, idx uint64) (int64, error) {
+	base, ok := cu.Val(AttrRnglistsBase).(int64)
+	if !ok && u.split != nil {
+		base = u.split.listsBase
+	}
+	size := uint64(4)
+	if u.is64 {
+		size = 8
+	}
+	b := makeBuf(d, u, "rnglists", 0, d.rngLists)
+	b.skip(int(uint64(base) + idx*size))
+	var off uint64
+	if u.is64 {
+		off = b.uint64()
+	} else {
+		off = uint64(b.uint32())
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return base + int64(off), nil
+}
+
 // dwarf5Ranges interpets a debug_rnglists sequence, see DWARFv5 section
 // 2.17.3 (page 53).
 func (d *Data) dwarf5Ranges(u *unit, cu *Entry, base uint64, ranges int64, ret [][2]uint64) ([][2]uint64, error) {
@@ -1138,6 +1194,10 @@ func (d *Data) dwarf5Ranges(u *unit, cu *Entry, base uint64, ranges int64, ret [
 
 // debugAddr returns the address at idx in debug_addr
 func (d *Data) debugAddr(format dataFormat, addrBase, idx uint64) (uint64, error) {
+	// ADDED: a split unit uses the addresses of its skeleton unit
+	if u, ok := format.(*unit); ok && u.split != nil {
+		addrBase = u.split.addrBase
+	}
 	off := idx*uint64(format.addrsize()) + addrBase
 
 	if uint64(int(off)) != off {
@@ -0,0 +1,547 @@
+// Location expressions, added by @vsoch
+// See DWARFv5 section 2.5 (DWARF expressions) and 2.6 (location descriptions).
+
+package dwarf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PieceKind says where (part of) a value lives
+type PieceKind int
+
+const (
+	PieceRegister        PieceKind = iota // in Register
+	PieceMemory                           // in memory, at the address Base + Offset
+	PieceValue                            // not stored, the value is Base + Offset (DW_OP_stack_value)
+	PieceImplicitValue                    // not stored, the value is the bytes in Value
+	PieceImplicitPointer                  // a pointer to the variable at DIE Pointer, plus Offset
+	PieceOptimizedOut                     // a piece with no location
+)
+
+func (k PieceKind) String() string {
+	switch k {
+	case PieceRegister:
+		return "register"
+	case PieceMemory:
+		return "memory"
+	case PieceValue:
+		return "value"
+	case PieceImplicitValue:
+		return "implicit_value"
+	case PieceImplicitPointer:
+		return "implicit_pointer"
+	case PieceOptimizedOut:
+		return "optimized_out"
+	}
+	return "unknown"
+}
+
+// BaseKind is what an address or value is relative to. We don't know the
+// contents of registers or memory, so expressions are evaluated symbolically.
+type BaseKind int
+
+const (
+	BaseNone       BaseKind = iota // Offset is a constant (or an absolute address)
+	BaseRegister                   // the contents of Register
+	BaseFrameBase                  // the function's DW_AT_frame_base
+	BaseCFA                        // the canonical frame address (DW_OP_call_frame_cfa)
+	BaseEntryValue                 // the contents of Register when the function was entered
+	BaseTLS                        // the thread local storage block
+	BaseUnknown                    // computed from memory or unknown values
+)
+
+func (k BaseKind) String() string {
+	switch k {
+	case BaseNone:
+		return "address"
+	case BaseRegister:
+		return "register"
+	case BaseFrameBase:
+		return "frame_base"
+	case BaseCFA:
+		return "cfa"
+	case BaseEntryValue:
+		return "entry_value"
+	case BaseTLS:
+		return "tls"
+	}
+	return "unknown"
+}
+
+// A Piece is where some (or all) of a value is stored
+type Piece struct {
+	Kind      PieceKind
+	Base      BaseKind // what an address or value is relative to
+	Register  int      // DWARF register number (of a register piece, or the base)
+	Offset    int64    // added to the base
+	Value     []byte   // the bytes of an implicit value
+	Pointer   Offset   // the DIE an implicit pointer points to
+	Size      int64    // bytes of the value in this piece, 0 if the whole value
+	BitSize   int64    // for DW_OP_bit_piece
+	BitOffset int64
+	Type      Offset // the base type of a typed constant (DW_OP_const_type), or 0
+}
+
+// A Location is where a value is stored, possibly split into pieces
+type Location struct {
+	Pieces []Piece
+}
+
+// String describes a location, e.g., "reg5", "[fbreg-20]" or "reg0:8 reg1:8"
+func (l *Location) String() string {
+	parts := []string{}
+	for _, p := range l.Pieces {
+		s := p.String()
+		if p.Size != 0 {
+			s += fmt.Sprintf(":%d", p.Size)
+		} else if p.BitSize != 0 {
+			s += fmt.Sprintf(":%dbits+%d", p.BitSize, p.BitOffset)
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// String describes one piece
+func (p Piece) String() string {
+	base := func() string {
+		s := ""
+		switch p.Base {
+		case BaseNone:
+			return fmt.Sprintf("0x%x", p.Offset)
+		case BaseRegister:
+			s = fmt.Sprintf("breg%d", p.Register)
+		case BaseEntryValue:
+			s = fmt.Sprintf("entry(reg%d)", p.Register)
+		case BaseFrameBase:
+			s = "fbreg"
+		default:
+			s = p.Base.String()
+		}
+		if p.Offset != 0 {
+			s += fmt.Sprintf("%+d", p.Offset)
+		}
+		return s
+	}
+	switch p.Kind {
+	case PieceRegister:
+		return fmt.Sprintf("reg%d", p.Register)
+	case PieceMemory:
+		return "[" + base() + "]"
+	case PieceValue:
+		return base()
+	case PieceImplicitValue:
+		return fmt.Sprintf("value(%x)", p.Value)
+	case PieceImplicitPointer:
+		return fmt.Sprintf("pointer(0x%x%+d)", p.Pointer, p.Offset)
+	}
+	return "optimized_out"
+}
+
+// IsRegister returns true for a location entirely in one register
+func (l *Location) IsRegister() bool {
+	return len(l.Pieces) == 1 && l.Pieces[0].Kind == PieceRegister
+}
+
+// An element of the expression stack: Base + Offset
+type stackValue struct {
+	base     BaseKind
+	register int
+	offset   int64
+	typ      Offset // the base type of a typed constant
+}
+
+// EvaluateLocation returns the location of attr (e.g., AttrLocation) of entry e at pc
+func (d *Data) EvaluateLocation(e *Entry, attr Attr, pc uint64) (*Location, error) {
+	expr, err := d.LocationExpr(e, attr, pc)
+	if err != nil {
+		return nil, err
+	}
+	return d.DecodeLocation(e, expr)
+}
+
+// DecodeLocation evaluates a location expression for entry e. Values that
+// depend on the program state (registers, memory) are kept symbolic.
+func (d *Data) DecodeLocation(e *Entry, expr []byte) (*Location, error) {
+	var u *unit
+	if uidx := d.offsetToUnit(e.Offset); uidx >= 0 && uidx < len(d.unit) {
+		u = &d.unit[uidx]
+	}
+	if u == nil {
+		return nil, errors.New("no unit for entry")
+	}
+
+	// Indexed addresses and constants (DWARF 5) need the unit's address base
+	var addrBase uint64
+	if cu, _, err := d.baseAddressForEntry(e); err == nil && cu != nil {
+		base, _ := cu.Val(AttrAddrBase).(int64)
+		addrBase = uint64(base)
+	}
+	return d.evaluate(u, addrBase, expr)
+}
+
+// evaluate runs the expression stack machine
+func (d *Data) evaluate(u *unit, addrBase uint64, expr []byte) (*Location, error) {
+	loc := &Location{}
+	stack := []stackValue{}
+
+	// The location being described, before a piece (or the end) completes it
+	var current *Piece
+
+	pop := func() (stackValue, error) {
+		if len(stack) == 0 {
+			return stackValue{}, errors.New("location expression stack underflow")
+		}
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return top, nil
+	}
+	push := func(v stackValue) { stack = append(stack, v) }
+	constant := func(c int64) { push(stackValue{base: BaseNone, offset: c}) }
+	unknown := func() { push(stackValue{base: BaseUnknown}) }
+
+	// finish completes the current piece, with an optional size
+	finish := func(size, bitSize, bitOffset int64) {
+		piece := Piece{Kind: PieceOptimizedOut}
+		if current != nil {
+			piece = *current
+		} else if len(stack) > 0 {
+			top := stack[len(stack)-1]
+			piece = Piece{Kind: PieceMemory, Base: top.base, Register: top.register, Offset: top.offset, Type: top.typ}
+		}
+		piece.Size, piece.BitSize, piece.BitOffset = size, bitSize, bitOffset
+		loc.Pieces = append(loc.Pieces, piece)
+		current = nil
+		stack = stack[:0]
+	}
+
+	b := makeBuf(d, u, "location", 0, expr)
+	for len(b.data) > 0 {
+		op := b.uint8()
+		switch {
+		case op >= opLit0 && op < opLit0+32:
+			constant(int64(op - opLit0))
+		case op >= opReg0 && op < opReg0+32:
+			current = &Piece{Kind: PieceRegister, Register: int(op - opReg0)}
+		case op >= opBreg0 && op < opBreg0+32:
+			push(stackValue{base: BaseRegister, register: int(op - opBreg0), offset: b.int()})
+		default:
+			switch op {
+
+			// Constants
+			case opAddr:
+				constant(int64(b.addr()))
+			case opAddrx, opConstx:
+				addr, err := d.debugAddr(u, addrBase, b.uint())
+				if err != nil {
+					return nil, err
+				}
+				constant(int64(addr))
+			case opConst1u:
+				constant(int64(b.uint8()))
+			case opConst1s:
+				constant(int64(int8(b.uint8())))
+			case opConst2u:
+				constant(int64(b.uint16()))
+			case opConst2s:
+				constant(int64(int16(b.uint16())))
+			case opConst4u:
+				constant(int64(b.uint32()))
+			case opConst4s:
+				constant(int64(int32(b.uint32())))
+			case opConst8u, opConst8s:
+				constant(int64(b.uint64()))
+			case opConstu:
+				constant(int64(b.uint()))
+			case opConsts:
+				constant(b.int())
+			case opConstType, opGNUConstType:
+				typ := u.base + Offset(b.uint())
+				size := int(b.uint8())
+				value := b.bytes(size)
+				var c int64
+				for i := range value {
+					if d.bigEndian {
+						c = c<<8 | int64(value[i])
+					} else {
+						c = c<<8 | int64(value[len(value)-1-i])
+					}
+				}
+				push(stackValue{base: BaseNone, offset: c, typ: typ})
+
+			// Register based addresses and values
+			case opRegx:
+				current = &Piece{Kind: PieceRegister, Register: int(b.uint())}
+			case opBregx:
+				reg := int(b.uint())
+				push(stackValue{base: BaseRegister, register: reg, offset: b.int()})
+			case opRegvalType:
+				reg := int(b.uint())
+				b.uint()
+				push(stackValue{base: BaseRegister, register: reg})
+			case opFbreg:
+				push(stackValue{base: BaseFrameBase, offset: b.int()})
+			case opCallFrameCFA:
+				push(stackValue{base: BaseCFA})
+			case opFormTLSAddress, opGNUPushTLSAddress:
+				top, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				push(stackValue{base: BaseTLS, offset: top.offset})
+			case opPushObjAddr:
+				unknown()
+
+			// The value a register had when the function was entered
+			case opEntryValue, opGNUEntryValue:
+				inner, err := d.evaluate(u, addrBase, b.bytes(int(b.uint())))
+				if err != nil {
+					return nil, err
+				}
+				if inner.IsRegister() {
+					push(stackValue{base: BaseEntryValue, register: inner.Pieces[0].Register})
+				} else {
+					unknown()
+				}
+
+			// We don't know what is in memory
+			case opDeref, opXderef:
+				if _, err := pop(); err != nil {
+					return nil, err
+				}
+				unknown()
+			case opDerefSize, opXderefSize:
+				b.uint8()
+				if _, err := pop(); err != nil {
+					return nil, err
+				}
+				unknown()
+			case opDerefType, opXderefType:
+				b.uint8()
+				b.uint()
+				if _, err := pop(); err != nil {
+					return nil, err
+				}
+				unknown()
+			case opConvert, opReinterpret:
+				b.uint()
+			case opGNUParameterRef:
+				b.uint32()
+				unknown()
+
+			// Stack operations
+			case opDup, opOver, opPick:
+				index := 0
+				if op == opOver {
+					index = 1
+				} else if op == opPick {
+					index = int(b.uint8())
+				}
+				if index >= len(stack) {
+					return nil, errors.New("location expression stack underflow")
+				}
+				push(stack[len(stack)-1-index])
+			case opDrop:
+				if _, err := pop(); err != nil {
+					return nil, err
+				}
+			case opSwap:
+				if len(stack) < 2 {
+					return nil, errors.New("location expression stack underflow")
+				}
+				n := len(stack)
+				stack[n-1], stack[n-2] = stack[n-2], stack[n-1]
+			case opRot:
+				if len(stack) < 3 {
+					return nil, errors.New("location expression stack underflow")
+				}
+				n := len(stack)
+				stack[n-1], stack[n-2], stack[n-3] = stack[n-2], stack[n-3], stack[n-1]
+
+			// Arithmetic and logic
+			case opPlusUconst:
+				top, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				top.offset += int64(b.uint())
+				push(top)
+			case opAbs, opNeg, opNot:
+				top, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				push(unaryOp(op, top))
+			case opAnd, opDiv, opMinus, opMod, opMul, opOr, opPlus, opShl, opShr, opShra, opXor,
+				opEq, opGe, opGt, opLe, opLt, opNe:
+				second, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				first, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				push(binaryOp(op, first, second))
+
+			// Control flow
+			case opSkip:
+				skip := int(int16(b.uint16()))
+				if err := jump(&b, expr, skip); err != nil {
+					return nil, err
+				}
+			case opBra:
+				skip := int(int16(b.uint16()))
+				top, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				if top.base != BaseNone {
+					return nil, errors.New("cannot branch on a value that is not constant")
+				}
+				if top.offset != 0 {
+					if err := jump(&b, expr, skip); err != nil {
+						return nil, err
+					}
+				}
+			case opNop:
+
+			// Location descriptions
+			case opStackValue:
+				top, err := pop()
+				if err != nil {
+					return nil, err
+				}
+				current = &Piece{Kind: PieceValue, Base: top.base, Register: top.register, Offset: top.offset, Type: top.typ}
+			case opImplicitValue:
+				current = &Piece{Kind: PieceImplicitValue, Value: b.bytes(int(b.uint()))}
+			case opImplicitPointer, opGNUImplicitPointer:
+				var ref Offset
+				if u.is64 {
+					ref = Offset(b.uint64())
+				} else {
+					ref = Offset(b.uint32())
+				}
+				current = &Piece{Kind: PieceImplicitPointer, Pointer: ref, Offset: b.int()}
+			case opPiece:
+				finish(int64(b.uint()), 0, 0)
+			case opBitPiece:
+				size := int64(b.uint())
+				finish(0, size, int64(b.uint()))
+
+			default:
+				return nil, fmt.Errorf("unsupported location operation 0x%x", op)
+			}
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+	}
+
+	// An expression without pieces describes the whole value
+	if current != nil || len(stack) > 0 {
+		finish(0, 0, 0)
+	}
+	return loc, nil
+}
+
+// jump moves the expression buffer by skip bytes (from the current position)
+func jump(b *buf, expr []byte, skip int) error {
+	pos := len(expr) - len(b.data) + skip
+	if pos < 0 || pos > len(expr) {
+		return errors.New("location expression branch out of range")
+	}
+	b.data = expr[pos:]
+	b.off = Offset(pos)
+	return nil
+}
+
+// unaryOp applies a one operand operation
+func unaryOp(op uint8, v stackValue) stackValue {
+	if v.base != BaseNone {
+		return stackValue{base: BaseUnknown}
+	}
+	switch op {
+	case opAbs:
+		if v.offset < 0 {
+			v.offset = -v.offset
+		}
+	case opNeg:
+		v.offset = -v.offset
+	case opNot:
+		v.offset = ^v.offset
+	}
+	return v
+}
+
+// binaryOp applies a two operand operation. Adding or subtracting a constant
+// keeps a symbolic base, anything else on symbolic values is unknown.
+func binaryOp(op uint8, a, b stackValue) stackValue {
+	if op == opPlus && a.base == BaseNone {
+		a, b = b, a
+	}
+	if (op == opPlus || op == opMinus) && b.base == BaseNone {
+		if op == opPlus {
+			a.offset += b.offset
+		} else {
+			a.offset -= b.offset
+		}
+		return a
+	}
+	if a.base != BaseNone || b.base != BaseNone {
+		return stackValue{base: BaseUnknown}
+	}
+
+	x, y := a.offset, b.offset
+	result := int64(0)
+	boolean := func(v bool) int64 {
+		if v {
+			return 1
+		}
+		return 0
+	}
+	switch op {
+	case opAnd:
+		result = x & y
+	case opOr:
+		result = x | y
+	case opXor:
+		result = x ^ y
+	case opMul:
+		result = x * y
+	case opDiv:
+		if y == 0 {
+			return stackValue{base: BaseUnknown}
+		}
+		result = x / y
+	case opMod:
+		if y == 0 {
+			return stackValue{base: BaseUnknown}
+		}
+		result = int64(uint64(x) % uint64(y))
+	case opMinus:
+		result = x - y
+	case opShl:
+		result = x << uint64(y)
+	case opShr:
+		result = int64(uint64(x) >> uint64(y))
+	case opShra:
+		result = x >> uint64(y)
+	case opEq:
+		result = boolean(x == y)
+	case opGe:
+		result = boolean(x >= y)
+	case opGt:
+		result = boolean(x > y)
+	case opLe:
+		result = boolean(x <= y)
+	case opLt:
+		result = boolean(x < y)
+	case opNe:
+		result = boolean(x != y)
+	}
+	return stackValue{base: BaseNone, offset: result}
+}
@@ -0,0 +1,263 @@
+// Location lists, added by @vsoch
+// DWARF 2-4 store them in .debug_loc, and DWARF 5 in .debug_loclists.
+
+package dwarf
+
+import (
+	"errors"
+)
+
+// ErrNoLocation is returned when an attribute has no location at a PC
+var ErrNoLocation = errors.New("no location at pc")
+
+// LocationExpr returns the location expression that attr (e.g., AttrLocation
+// or AttrFrameBase) of entry e describes at pc. A single expression
+// (ClassExprLoc) is valid at every pc, and a location list is searched for
+// the entry covering pc.
+func (d *Data) LocationExpr(e *Entry, attr Attr, pc uint64) ([]byte, error) {
+	field := e.AttrField(attr)
+	if field == nil {
+		return nil, ErrNoLocation
+	}
+
+	var u *unit
+	if uidx := d.offsetToUnit(e.Offset); uidx >= 0 && uidx < len(d.unit) {
+		u = &d.unit[uidx]
+	}
+	if u == nil {
+		return nil, errors.New("no unit for entry")
+	}
+
+	// A split unit has its own location lists
+	if u.split != nil {
+		d = u.split.data
+	}
+
+	switch field.Class {
+	case ClassExprLoc, ClassBlock:
+		expr, _ := field.Val.([]byte)
+		return expr, nil
+
+	case ClassLocListPtr:
+		off, ok := field.Val.(int64)
+		if !ok {
+			return nil, ErrNoLocation
+		}
+		cu, base, err := d.baseAddressForEntry(e)
+		if err != nil {
+			return nil, err
+		}
+		if u.vers >= 5 {
+			return d.dwarf5Location(u, cu, base, off, pc)
+		}
+		if u.split != nil {
+			return d.splitLocation(u, base, off, pc)
+		}
+		return d.dwarf2Location(u, base, off, pc)
+
+	case ClassLocList:
+		idx, ok := field.Val.(uint64)
+		if !ok {
+			return nil, ErrNoLocation
+		}
+		cu, base, err := d.baseAddressForEntry(e)
+		if err != nil {
+			return nil, err
+		}
+		off, err := d.locListOffset(u, cu, idx)
+		if err != nil {
+			return nil, err
+		}
+		return d.dwarf5Location(u, cu, base, off, pc)
+	}
+	return nil, ErrNoLocation
+}
+
+// locListOffset finds the offset of a list by index (DW_FORM_loclistx). The
+// offsets table follows the unit's DW_AT_loclists_base, and is relative to it.
+func (d *Data) locListOffset(u *unit, cu *Entry, idx uint64) (int64, error) {
+	base, ok := cu.Val(AttrLoclistsBase).(int64)
+	if !ok && u.split != nil {
+		base = u.split.listsBase
+	}
+	size := uint64(4)
+	if u.is64 {
+		size = 8
+	}
+	b := makeBuf(d, u, "loclists", 0, d.LocLists)
+	b.skip(int(uint64(base) + idx*size))
+	var off uint64
+	if u.is64 {
+		off = b.uint64()
+	} else {
+		off = uint64(b.uint32())
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return base + int64(off), nil
+}
+
+// dwarf2Location searches a .debug_loc list, see DWARFv4 section 2.6.2
+func (d *Data) dwarf2Location(u *unit, base uint64, off int64, pc uint64) ([]byte, error) {
+	if off < 0 || off >= int64(len(d.locs)) {
+		return nil, DecodeError{"loc", Offset(off), "offset out of range"}
+	}
+	b := makeBuf(d, u, "loc", Offset(off), d.locs[off:])
+	for len(b.data) > 0 {
+		low := b.addr()
+		high := b.addr()
+		if b.err != nil {
+			return nil, b.err
+		}
+		if low == 0 && high == 0 {
+			break
+		}
+
+		// A base address selection entry
+		if low == ^uint64(0)>>uint((8-u.addrsize())*8) {
+			base = high
+			continue
+		}
+		expr := b.bytes(int(b.uint16()))
+		if base+low <= pc && pc < base+high {
+			return expr, b.err
+		}
+	}
+	return nil, ErrNoLocation
+}
+
+// splitLocation searches a .debug_loc.dwo list of a split unit before DWARF 5,
+// which uses indexed addresses (a GNU extension)
+func (d *Data) splitLocation(u *unit, base uint64, off int64, pc uint64) ([]byte, error) {
+	if off < 0 || off >= int64(len(d.locs)) {
+		return nil, DecodeError{"loc.dwo", Offset(off), "offset out of range"}
+	}
+	b := makeBuf(d, u, "loc.dwo", Offset(off), d.locs[off:])
+	for {
+		var start, end uint64
+		var err error
+		switch b.uint8() {
+		case lleGNUEndOfList:
+			return nil, ErrNoLocation
+
+		case lleGNUBaseAddress:
+			if base, err = d.debugAddr(u, 0, b.uint()); err != nil {
+				return nil, err
+			}
+			continue
+
+		case lleGNUStartEnd:
+			if start, err = d.debugAddr(u, 0, b.uint()); err != nil {
+				return nil, err
+			}
+			if end, err = d.debugAddr(u, 0, b.uint()); err != nil {
+				return nil, err
+			}
+
+		case lleGNUStartLength:
+			if start, err = d.debugAddr(u, 0, b.uint()); err != nil {
+				return nil, err
+			}
+			end = start + uint64(b.uint32())
+
+		default:
+			return nil, DecodeError{"loc.dwo", b.off, "unknown location list entry"}
+		}
+
+		expr := b.bytes(int(b.uint16()))
+		if b.err != nil {
+			return nil, b.err
+		}
+		if start <= pc && pc < end {
+			return expr, nil
+		}
+	}
+}
+
+// dwarf5Location searches a .debug_loclists list, see DWARFv5 section 2.6.2
+func (d *Data) dwarf5Location(u *unit, cu *Entry, base uint64, off int64, pc uint64) ([]byte, error) {
+	var addrBase int64
+	if cu != nil {
+		addrBase, _ = cu.Val(AttrAddrBase).(int64)
+	}
+	if off < 0 || off >= int64(len(d.LocLists)) {
+		return nil, DecodeError{"loclists", Offset(off), "offset out of range"}
+	}
+
+	var fallback []byte
+	b := makeBuf(d, u, "loclists", Offset(off), d.LocLists[off:])
+	for {
+		var start, end uint64
+		var err error
+		opcode := b.uint8()
+		if b.err != nil {
+			return nil, b.err
+		}
+		switch opcode {
+		case lleEndOfList:
+			if fallback != nil {
+				return fallback, nil
+			}
+			return nil, ErrNoLocation
+
+		case lleBaseAddressx:
+			base, err = d.debugAddr(u, uint64(addrBase), b.uint())
+			if err != nil {
+				return nil, err
+			}
+			continue
+
+		case lleBaseAddress:
+			base = b.addr()
+			continue
+
+		case lleStartxEndx:
+			if start, err = d.debugAddr(u, uint64(addrBase), b.uint()); err != nil {
+				return nil, err
+			}
+			if end, err = d.debugAddr(u, uint64(addrBase), b.uint()); err != nil {
+				return nil, err
+			}
+
+		case lleStartxLength:
+			if start, err = d.debugAddr(u, uint64(addrBase), b.uint()); err != nil {
+				return nil, err
+			}
+			end = start + b.uint()
+
+		case lleOffsetPair:
+			start = base + b.uint()
+			end = base + b.uint()
+
+		case lleStartEnd:
+			start = b.addr()
+			end = b.addr()
+
+		case lleStartLength:
+			start = b.addr()
+			end = start + b.uint()
+
+		case lleDefaultLocation:
+			fallback = b.bytes(int(b.uint()))
+			continue
+
+		// DW_LLE_GNU_view_pair precedes an entry with the location views
+		case lleGNUViewPair:
+			b.uint()
+			b.uint()
+			continue
+
+		default:
+			return nil, DecodeError{"loclists", b.off, "unknown location list entry"}
+		}
+
+		expr := b.bytes(int(b.uint()))
+		if b.err != nil {
+			return nil, b.err
+		}
+		if start <= pc && pc < end {
+			return expr, nil
+		}
+	}
+}
@@ -39,8 +39,17 @@ type Data struct {
 	order       binary.ByteOrder
 	TypeCache   map[Offset]Type
 	StructCache map[string]*StructType
+	Structs     map[StructKey]*StructType
 	typeSigs    map[uint64]*typeUnit
-	unit        []unit
+	typeIndex   *TypeIndex
+
+	// ADDED: skeleton units of split DWARF, by ID
+	skeletonUnits map[uint64]*skeleton
+
+	// Extra is other debug information (e.g., of another library) to find the
+	// definition of a declared type in, see Definition
+	Extra []*Data
+	unit  []unit
 }
 
 var errSegmentSelector = errors.New("non-zero segment_selector size not supported")
@@ -71,6 +80,7 @@ func New(abbrev, aranges, frame, info, line, pubnames, ranges, str, loc []byte)
 		abbrevCache: make(map[uint64]abbrevTable),
 		TypeCache:   make(map[Offset]Type),
 		StructCache: make(map[string]*StructType),
+		Structs:     make(map[StructKey]*StructType),
 		typeSigs:    make(map[uint64]*typeUnit),
 	}
 
@@ -126,7 +136,7 @@ func (d *Data) AddSection(name string, contents []byte) error {
 	var err error
 	switch name {
 	// Added to get location lists @vsoch
-	case ".debug_loc":
+	case ".debug_loclists":
 		d.LocLists = contents
 	case ".debug_addr":
 		d.addr = contents
@@ -0,0 +1,163 @@
+// Type names and definitions, added by @vsoch
+// A struct, union, or class can be declared in one compile unit (e.g., an
+// opaque handle) and defined in another, or in another library.
+
+package dwarf
+
+import (
+	"sort"
+)
+
+// A TypeIndex has the qualified names (with namespaces and enclosing types) of
+// the struct, union, and class types that can be shared between compile units.
+// Types in an anonymous namespace or a function are local, and not included.
+// An anonymous type named by a typedef is under its own name (see TypedefKey),
+// since in C it is not the same type as a struct with the tag of the typedef.
+type TypeIndex struct {
+	Names       map[Offset]string   // the name of each type entry
+	Definitions map[string][]Offset // the entries that define each name (not declarations)
+	Units       map[Offset]string   // the name of each compile unit
+}
+
+// indexScope is a namespace or type that a type is declared in
+type indexScope struct {
+	name  string
+	local bool // an anonymous namespace, or a function
+}
+
+// TypeIndex reads the names of all types (once)
+func (d *Data) TypeIndex() *TypeIndex {
+	if d.typeIndex != nil {
+		return d.typeIndex
+	}
+	index := &TypeIndex{Names: map[Offset]string{}, Definitions: map[string][]Offset{}, Units: map[Offset]string{}}
+	d.typeIndex = index
+
+	// A typedef can name an anonymous type (typedef struct { ... } config;)
+	anonymous := map[Offset]bool{}
+	typedefs := map[Offset]string{}
+
+	scopes := []indexScope{}
+	r := d.Reader()
+	for e, err := r.Next(); e != nil && err == nil; e, err = r.Next() {
+
+		// The end of the children of a scope
+		if e.Tag == 0 {
+			if len(scopes) > 0 {
+				scopes = scopes[:len(scopes)-1]
+			}
+			continue
+		}
+
+		name, _ := e.Val(AttrName).(string)
+		local := len(scopes) > 0 && scopes[len(scopes)-1].local
+		switch e.Tag {
+		case TagCompileUnit, TagPartialUnit:
+			index.Units[e.Offset] = name
+			scopes = []indexScope{}
+
+		case TagStructType, TagClassType, TagUnionType:
+			declaration, _ := e.Val(AttrDeclaration).(bool)
+			switch {
+			case local:
+			case name == "":
+				if !declaration {
+					anonymous[e.Offset] = true
+				}
+			default:
+				qualified := qualifiedName(scopes, name)
+				index.Names[e.Offset] = qualified
+				if !declaration {
+					index.Definitions[qualified] = append(index.Definitions[qualified], e.Offset)
+				}
+			}
+
+		case TagTypedef:
+			if off, ok := e.Val(AttrType).(Offset); ok && name != "" && !local {
+				typedefs[off] = qualifiedName(scopes, name)
+			}
+		}
+
+		if e.Children {
+			scope := indexScope{name: name, local: local}
+			switch e.Tag {
+			case TagCompileUnit, TagPartialUnit:
+				scope.name = ""
+			case TagNamespace:
+				scope.local = local || name == ""
+			case TagStructType, TagClassType, TagUnionType:
+			default:
+				scope.local = true
+			}
+			scopes = append(scopes, scope)
+		}
+	}
+
+	for off := range anonymous {
+		if typedef, ok := typedefs[off]; ok {
+			name := TypedefKey(typedef)
+			index.Names[off] = name
+			index.Definitions[name] = append(index.Definitions[name], off)
+		}
+	}
+	for _, offsets := range index.Definitions {
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	}
+	return index
+}
+
+// TypedefKey is the name an anonymous type is indexed under when a typedef
+// names it (typedef struct { ... } config; is "typedef config")
+func TypedefKey(typedef string) string {
+	return "typedef " + typedef
+}
+
+// qualifiedName joins the names of the namespaces and types a type is declared in
+func qualifiedName(scopes []indexScope, name string) string {
+	qualified := ""
+	for _, scope := range scopes {
+		if scope.name != "" {
+			qualified += scope.name + "::"
+		}
+	}
+	return qualified + name
+}
+
+// Definition returns the definition of a declared (incomplete) struct, union,
+// or class: the first in a compile unit of this file, or else in Extra. It
+// returns nil if there is none, and t if it is not a declaration.
+func (d *Data) Definition(t *StructType) *StructType {
+	if !t.Incomplete {
+		return t
+	}
+	name := t.StructName
+	if d.Structs[StructKey{t.Unit, t.Offset}] == t {
+		if qualified, ok := d.TypeIndex().Names[t.Offset]; ok {
+			name = qualified
+		}
+	}
+	if name == "" {
+		return nil
+	}
+	if definition := d.definitionByName(name); definition != nil {
+		return definition
+	}
+	for _, extra := range d.Extra {
+		if definition := extra.definitionByName(name); definition != nil {
+			return definition
+		}
+	}
+	return nil
+}
+
+// definitionByName returns the first definition of a qualified name
+func (d *Data) definitionByName(name string) *StructType {
+	for _, off := range d.TypeIndex().Definitions[name] {
+		if t, err := d.Type(off); err == nil {
+			if definition, ok := t.(*StructType); ok && !definition.Incomplete {
+				return definition
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+// Split DWARF, added by @vsoch
+// A binary built with -gsplit-dwarf only has a skeleton unit for each compile
+// unit, with the name and ID of a .dwo file that has the debug information
+// (or a .dwp package file that combines them). Addresses stay in the binary:
+// a split unit indexes the .debug_addr section of its skeleton unit.
+
+package dwarf
+
+import (
+	"errors"
+	"strconv"
+)
+
+// GNU extensions for split DWARF, from before DWARF 5
+const (
+	attrGNUDwoName    Attr = 0x2130
+	attrGNUDwoID      Attr = 0x2131
+	attrGNURangesBase Attr = 0x2132
+	attrGNUAddrBase   Attr = 0x2133
+)
+
+// A SplitUnit is a compile unit whose debug information is in a .dwo file
+type SplitUnit struct {
+	Name    string // DW_AT_dwo_name, usually relative to CompDir
+	CompDir string
+	ID      uint64
+}
+
+// splitUnit is what a split unit needs from its skeleton unit, and the
+// sections of the .dwo file (or of the unit in a .dwp file) it is read from
+type splitUnit struct {
+	data           *Data
+	addrBase       uint64
+	rangesBase     int64
+	lowpc          uint64
+	strOffsetsBase uint64
+	listsBase      int64 // the offsets tables of .debug_loclists.dwo and .debug_rnglists.dwo
+}
+
+// skeleton is a skeleton unit, and whether its split unit has been added
+type skeleton struct {
+	SplitUnit
+	addrBase   uint64
+	rangesBase int64
+	lowpc      uint64
+	added      bool
+}
+
+// skeletons finds the skeleton units (once)
+func (d *Data) skeletons() map[uint64]*skeleton {
+	if d.skeletonUnits != nil {
+		return d.skeletonUnits
+	}
+	d.skeletonUnits = map[uint64]*skeleton{}
+	for i := range d.unit {
+		u := &d.unit[i]
+		if u.split != nil {
+			continue
+		}
+		b := makeBuf(d, u, "info", u.off, u.data)
+		cu := b.entry(nil, u.atable, u.base, u.vers)
+		if b.err != nil || cu == nil {
+			continue
+		}
+
+		s := &skeleton{}
+		switch name, ok := cu.Val(AttrDwoName).(string); {
+		case ok && u.utype == utSkeleton:
+			s.Name, s.ID = name, u.id
+			base, _ := cu.Val(AttrAddrBase).(int64)
+			s.addrBase = uint64(base)
+		default:
+			id, ok := cu.Val(attrGNUDwoID).(int64)
+			if s.Name, _ = cu.Val(attrGNUDwoName).(string); s.Name == "" || !ok {
+				continue
+			}
+			s.ID = uint64(id)
+			base, _ := cu.Val(attrGNUAddrBase).(int64)
+			s.addrBase = uint64(base)
+			s.rangesBase, _ = cu.Val(attrGNURangesBase).(int64)
+		}
+		s.CompDir, _ = cu.Val(AttrCompDir).(string)
+		_, s.lowpc, _ = d.baseAddressForEntry(cu)
+		d.skeletonUnits[s.ID] = s
+	}
+	return d.skeletonUnits
+}
+
+// SplitUnits returns the skeleton units whose split unit has not been added
+func (d *Data) SplitUnits() []SplitUnit {
+	units := []SplitUnit{}
+	for _, s := range d.skeletons() {
+		if !s.added {
+			units = append(units, s.SplitUnit)
+		}
+	}
+	return units
+}
+
+// AddSplitUnits adds the split units of a .dwo file, given its sections by
+// name without the prefix and suffix (e.g., "info" for .debug_info.dwo).
+// Units that no skeleton unit refers to (e.g., a stale .dwo) are skipped.
+func (d *Data) AddSplitUnits(sections map[string][]byte) error {
+	if len(sections["info"]) == 0 {
+		return errors.New("no .debug_info.dwo section")
+	}
+	sd := &Data{
+		abbrev:     sections["abbrev"],
+		info:       sections["info"],
+		line:       sections["line"],
+		locs:       sections["loc"],
+		str:        sections["str"],
+		strOffsets: sections["str_offsets"],
+		rngLists:   sections["rnglists"],
+		LocLists:   sections["loclists"],
+
+		// Addresses and ranges (before DWARF 5) are in the binary
+		addr:   d.addr,
+		ranges: d.ranges,
+
+		abbrevCache: make(map[uint64]abbrevTable),
+		bigEndian:   d.bigEndian,
+		order:       d.order,
+	}
+	units, err := sd.parseUnits()
+	if err != nil {
+		return err
+	}
+
+	// The new units follow the last unit (their offsets don't overlap)
+	delta := Offset(len(d.info))
+	if len(d.unit) > 0 {
+		last := &d.unit[len(d.unit)-1]
+		if end := last.off + Offset(len(last.data)); end > delta {
+			delta = end
+		}
+	}
+	delta = (delta + 15) &^ 15
+
+	skeletons := d.skeletons()
+	for _, u := range units {
+		id := u.id
+		if u.utype != utSplitCompile {
+			if u.vers >= 5 {
+				continue
+			}
+			b := makeBuf(sd, &u, "info", u.off, u.data)
+			cu := b.entry(nil, u.atable, u.base, u.vers)
+			if b.err != nil || cu == nil {
+				continue
+			}
+			gnuID, _ := cu.Val(attrGNUDwoID).(int64)
+			id = uint64(gnuID)
+		}
+		s, ok := skeletons[id]
+		if !ok || s.added {
+			continue
+		}
+		s.added = true
+
+		u.split = &splitUnit{data: sd, addrBase: s.addrBase, rangesBase: s.rangesBase, lowpc: s.lowpc}
+		if u.vers >= 5 {
+			// The offsets tables follow the section (or contribution) headers
+			u.split.strOffsetsBase, u.split.listsBase = 8, 12
+			if u.is64 {
+				u.split.strOffsetsBase, u.split.listsBase = 16, 20
+			}
+		}
+
+		u.base += delta
+		u.off += delta
+		d.unit = append(d.unit, u)
+	}
+	sd.unit = d.unit
+	d.typeIndex = nil
+	return nil
+}
+
+// The kinds of section contributions in a .dwp package index
+// (DW_SECT_*, version 2 is the GNU extension and 5 is DWARF 5)
+const (
+	sectInfo       = 1
+	sectTypes      = 2
+	sectAbbrev     = 3
+	sectLine       = 4
+	sectLoc        = 5 // .debug_loclists.dwo in version 5
+	sectStrOffsets = 6
+	sectRngLists   = 8 // version 5 only
+)
+
+// AddPackage adds the split units of a .dwp package file, given its sections
+// by name like AddSplitUnits. The .debug_cu_index section says where each
+// unit (by ID) and its part of each other section is.
+func (d *Data) AddPackage(sections map[string][]byte) error {
+	index := sections["cu_index"]
+	if len(index) < 16 {
+		return errors.New("no .debug_cu_index section")
+	}
+	b := makeBuf(d, unknownFormat{}, "cu_index", 0, index)
+	version := b.uint32()
+	if d.bigEndian && version > 0xffff {
+		version >>= 16
+	}
+	version &= 0xffff
+	if version != 2 && version != 5 {
+		return errors.New("unsupported .debug_cu_index version " + strconv.Itoa(int(version)))
+	}
+	columns := int(b.uint32())
+	rows := int(b.uint32())
+	slots := int(b.uint32())
+
+	ids := make([]uint64, slots)
+	for i := range ids {
+		ids[i] = b.uint64()
+	}
+	indices := make([]uint32, slots)
+	for i := range indices {
+		indices[i] = b.uint32()
+	}
+	kinds := make([]uint32, columns)
+	for i := range kinds {
+		kinds[i] = b.uint32()
+	}
+	offsets := make([]uint32, rows*columns)
+	for i := range offsets {
+		offsets[i] = b.uint32()
+	}
+	sizes := make([]uint32, rows*columns)
+	for i := range sizes {
+		sizes[i] = b.uint32()
+	}
+	if b.err != nil {
+		return b.err
+	}
+
+	// Kind 8 is DW_SECT_MACRO in version 2 (and kind 7 is macro information
+	// in both), which we don't read
+	names := map[uint32]string{sectInfo: "info", sectTypes: "types", sectAbbrev: "abbrev",
+		sectLine: "line", sectLoc: "loc", sectStrOffsets: "str_offsets"}
+	if version == 5 {
+		names[sectLoc] = "loclists"
+		names[sectRngLists] = "rnglists"
+	}
+
+	skeletons := d.skeletons()
+	for slot, row := range indices {
+		if row == 0 || int(row) > rows {
+			continue
+		}
+		if s, ok := skeletons[ids[slot]]; !ok || s.added {
+			continue
+		}
+
+		// The strings are shared, and everything else is the unit's part
+		unit := map[string][]byte{"str": sections["str"]}
+		for column, kind := range kinds {
+			name, ok := names[kind]
+			if !ok || sections[name] == nil {
+				continue
+			}
+			i := int(row-1)*columns + column
+			start, end := uint64(offsets[i]), uint64(offsets[i])+uint64(sizes[i])
+			if end > uint64(len(sections[name])) {
+				return DecodeError{"cu_index", Offset(i), "contribution out of range"}
+			}
+			unit[name] = sections[name][start:end]
+		}
+		if err := d.AddSplitUnits(unit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
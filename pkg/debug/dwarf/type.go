@@ -8,7 +8,10 @@
 
 package dwarf
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // A Type conventionally represents a pointer to any of the
 // specific Type structures (CharType, StructType, etc.).
@@ -113,6 +116,108 @@ type ArrayType struct {
 	Type          Type
 	StrideBitSize int64 // if > 0, number of bits to hold each element
 	Count         int64 // if == -1, an incomplete array, like char x[].
+
+	// ADDED: the bounds of each dimension, which can be dynamic (e.g.,
+	// Fortran assumed shape arrays), and where the data is when the object
+	// is a descriptor (DW_AT_data_location)
+	Bounds       []ArrayBound
+	DataLocation []byte
+	Rank         Bound // for an assumed rank array (DW_AT_rank)
+}
+
+// ADDED: An ArrayBound describes one dimension of an array (DW_TAG_subrange_type)
+type ArrayBound struct {
+	Lower  Bound
+	Upper  Bound
+	Count  Bound
+	Stride Bound // in bytes
+}
+
+// ADDED: A Bound is a constant, an expression evaluated at run time (usually
+// relative to the address of a descriptor), or a variable that holds it
+type Bound struct {
+	Constant   int64
+	IsConstant bool
+	Expr       []byte
+	Variable   Offset // the entry of the variable, if there is one
+}
+
+// Known determines if the compiler described the bound
+func (b Bound) Known() bool { return b.IsConstant || b.Expr != nil || b.Variable != 0 }
+
+// Dynamic determines if the bound is only known at run time
+func (b Bound) Dynamic() bool { return !b.IsConstant && b.Known() }
+
+// Dynamic determines if the shape of an array is only known at run time
+func (t *ArrayType) Dynamic() bool {
+	if t.DataLocation != nil || t.Rank.Known() {
+		return true
+	}
+	for _, bound := range t.Bounds {
+		if bound.Lower.Dynamic() || bound.Upper.Dynamic() || bound.Count.Dynamic() || bound.Stride.Dynamic() {
+			return true
+		}
+	}
+	return false
+}
+
+// DescriptorOffset returns where in an array descriptor a bound (or the data
+// location) is read from, for the common expression that pushes the address
+// of the descriptor, adds an offset, and reads the value there
+func DescriptorOffset(expr []byte) (int64, bool) {
+	if len(expr) < 2 || expr[0] != opPushObjAddr {
+		return 0, false
+	}
+	offset, i := int64(0), 1
+	if expr[i] == opPlusUconst {
+		shift := uint(0)
+		for i++; i < len(expr); i++ {
+			offset |= int64(expr[i]&0x7f) << shift
+			shift += 7
+			if expr[i]&0x80 == 0 {
+				break
+			}
+		}
+		i++
+	}
+	switch {
+	case i == len(expr)-1 && expr[i] == opDeref:
+	case i == len(expr)-2 && expr[i] == opDerefSize:
+	default:
+		return 0, false
+	}
+	return offset, true
+}
+
+// readBound reads a bound attribute of a subrange or string type
+func readBound(e *Entry, attr Attr) Bound {
+	switch value := e.Val(attr).(type) {
+	case int64:
+		return Bound{Constant: value, IsConstant: true}
+	case uint64:
+		return Bound{Constant: int64(value), IsConstant: true}
+	case []byte:
+		return Bound{Expr: value}
+	case Offset:
+		return Bound{Variable: value}
+	}
+	return Bound{}
+}
+
+// ADDED: A StringType is a Fortran character type (DW_TAG_string_type)
+type StringType struct {
+	CommonType
+	Length Bound // in characters, dynamic for character(len=*)
+}
+
+func (t *StringType) String() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	if t.Length.IsConstant {
+		return "character(len=" + strconv.FormatInt(t.Length.Constant, 10) + ")"
+	}
+	return "character(len=*)"
 }
 
 func (t *ArrayType) String() string {
@@ -141,6 +246,38 @@ type PtrType struct {
 
 func (t *PtrType) String() string { return "*" + t.Type.String() }
 
+// ADDED: A RefType represents a C++ reference (or rvalue reference) type.
+type RefType struct {
+	CommonType
+	Type   Type
+	Rvalue bool // && instead of &
+}
+
+func (t *RefType) String() string {
+	if t.Rvalue {
+		return "&&" + t.Type.String()
+	}
+	return "&" + t.Type.String()
+}
+
+// ADDED: A PtrToMemberType represents a C++ pointer to member (data or function).
+type PtrToMemberType struct {
+	CommonType
+	Type  Type // the type of the member
+	Class Type // the class containing the member
+}
+
+func (t *PtrToMemberType) String() string {
+	return t.Type.String() + " " + t.Class.String() + "::*"
+}
+
+// IsFunction determines if this is a pointer to member function, which is
+// a function pointer and an adjustment for this (Itanium C++ ABI 2.3)
+func (t *PtrToMemberType) IsFunction() bool {
+	_, ok := t.Type.(*FuncType)
+	return ok
+}
+
 // A StructType represents a struct, union, or C++ class type.
 type StructType struct {
 	CommonType
@@ -148,6 +285,201 @@ type StructType struct {
 	Kind       string // "struct", "union", or "class".
 	Field      []*StructField
 	Incomplete bool // if true, struct, union, class is declared but not defined
+
+	// ADDED: C++ base classes and virtual methods declared in the type
+	Bases   []*StructBase
+	Methods []*VirtualMethod
+
+	// ADDED: a discriminated union (e.g., a Rust enum), if there is one
+	Variants *VariantPart
+
+	// ADDED: the template arguments of a C++ class template specialization
+	TemplateParams []*TemplateParam
+
+	// ADDED: where the type is defined (the offsets of its compile unit and
+	// entry in .debug_info), since a name can be defined in each compile unit
+	Unit   Offset
+	Offset Offset
+}
+
+// ADDED: A StructKey identifies a struct, union, or class definition by its
+// compile unit and entry
+type StructKey struct {
+	Unit   Offset
+	Offset Offset
+}
+
+// ADDED: Layout describes the layout of a struct, union, or class (the size,
+// the bases, and the name, type, and offset of each field), so definitions
+// with the same name in different compile units can be compared
+func (t *StructType) Layout() string {
+	var b strings.Builder
+	b.WriteString(t.Kind)
+	if t.StructName != "" {
+		b.WriteString(" " + t.StructName)
+	}
+	b.WriteString(" size=" + strconv.FormatInt(t.ByteSize, 10))
+	for _, base := range t.Bases {
+		b.WriteString("; base " + base.Type.String() + "@" + strconv.FormatInt(base.ByteOffset, 10))
+	}
+	for _, f := range t.Field {
+		b.WriteString("; " + f.Name + " " + f.Type.String() + "@" + strconv.FormatInt(f.ByteOffset, 10))
+		if f.BitSize > 0 {
+			b.WriteString(":" + strconv.FormatInt(f.BitOffset, 10) + "+" + strconv.FormatInt(f.BitSize, 10))
+		}
+	}
+	return b.String()
+}
+
+// ADDED: A TemplateParam is a template argument of a type or function
+// (DW_TAG_template_type_parameter or DW_TAG_template_value_parameter)
+type TemplateParam struct {
+	Name    string
+	Type    Type        // the type argument, or the type of a value argument
+	IsValue bool        // a value (non-type) argument
+	Value   interface{} // the value (int64, uint64 or []byte), if the compiler said
+}
+
+// TemplateParam reads a template parameter entry (the child of a type or function)
+func (d *Data) TemplateParam(e *Entry) (*TemplateParam, error) {
+	var err error
+	param := readTemplateParam(e, func(e *Entry) Type {
+		offset, ok := e.Val(AttrType).(Offset)
+		if !ok {
+			return new(VoidType)
+		}
+		var t Type
+		t, err = d.Type(offset)
+		return t
+	})
+	return param, err
+}
+
+// ADDED: readTemplateParam reads a template parameter with a function to read types
+func readTemplateParam(e *Entry, typeOf func(*Entry) Type) *TemplateParam {
+	param := &TemplateParam{IsValue: e.Tag == TagTemplateValueParameter, Type: typeOf(e)}
+	param.Name, _ = e.Val(AttrName).(string)
+	param.Value = e.Val(AttrConstValue)
+	return param
+}
+
+// ADDED: A VariantPart is a discriminated union (DW_TAG_variant_part). The
+// discriminant says which variant is present. When it has no member of its
+// own (it is stored in a niche of another variant), Discriminant is that field.
+type VariantPart struct {
+	Discriminant *StructField // nil if the compiler did not say
+	Variants     []*Variant
+}
+
+// ADDED: A Variant is one case of a discriminated union (DW_TAG_variant)
+type Variant struct {
+	Values []DiscrRange // the discriminant values for the variant, empty for the default
+	Field  []*StructField
+}
+
+// ADDED: A DiscrRange is a range of discriminant values (Low == High for one value)
+type DiscrRange struct {
+	Low  int64
+	High int64
+}
+
+// ADDED: A StructBase is a base class of a C++ class (DW_TAG_inheritance).
+type StructBase struct {
+	Type       Type
+	ByteOffset int64 // unknown (0) for a virtual base, which is found through the vtable
+	Virtual    bool
+	Access     string // "public", "protected" or "private"
+}
+
+// ADDED: A VirtualMethod is a virtual member function with its vtable slot
+// (DW_AT_vtable_elem_location).
+type VirtualMethod struct {
+	Name        string
+	LinkageName string
+	Slot        int64 // -1 if the compiler did not say
+	Deleting    bool  // the deleting destructor that follows a virtual destructor
+}
+
+// Vtable returns the virtual methods of a class by slot, including the ones
+// it inherits from its primary base (the non virtual base at offset 0).
+// A slot that we don't know the method for is nil.
+func (t *StructType) Vtable() []*VirtualMethod {
+	vtable := []*VirtualMethod{}
+	for _, base := range t.Bases {
+		parent, ok := base.Type.(*StructType)
+		if ok && !base.Virtual && base.ByteOffset == 0 {
+			vtable = append(vtable, parent.Vtable()...)
+			break
+		}
+	}
+	unknown := []*VirtualMethod{}
+	for _, method := range t.Methods {
+		if method.Slot < 0 {
+			unknown = append(unknown, method)
+			continue
+		}
+		vtable = setSlot(vtable, method.Slot, method)
+	}
+
+	// Compilers leave out the slot of a virtual destructor. It overrides
+	// the one inherited, or takes the first free slots (in declaration order).
+	for _, method := range unknown {
+		slot := int64(-1)
+		for i, existing := range vtable {
+			if existing == nil && slot < 0 {
+				slot = int64(i)
+			}
+			if existing != nil && isDestructor(method) && isDestructor(existing) && !existing.Deleting {
+				slot = int64(i)
+				break
+			}
+		}
+		if slot < 0 {
+			slot = int64(len(vtable))
+		}
+		placed := *method
+		placed.Slot = slot
+		vtable = setSlot(vtable, slot, &placed)
+	}
+	return vtable
+}
+
+// setSlot puts a method in a vtable, growing it if needed
+func setSlot(vtable []*VirtualMethod, slot int64, method *VirtualMethod) []*VirtualMethod {
+	for int64(len(vtable)) <= slot {
+		vtable = append(vtable, nil)
+	}
+	vtable[slot] = method
+	if !isDestructor(method) {
+		return vtable
+	}
+
+	// A virtual destructor has two slots, the complete object destructor (D1)
+	// and the deleting destructor (D0), see Itanium C++ ABI 2.5.2
+	complete := *method
+	complete.LinkageName = destructorVariant(method.LinkageName, "D1")
+	vtable[slot] = &complete
+	if int64(len(vtable)) == slot+1 {
+		vtable = append(vtable, nil)
+	}
+	if vtable[slot+1] == nil || vtable[slot+1].Deleting {
+		vtable[slot+1] = &VirtualMethod{Name: method.Name, Slot: slot + 1, Deleting: true,
+			LinkageName: destructorVariant(method.LinkageName, "D0")}
+	}
+	return vtable
+}
+
+func isDestructor(method *VirtualMethod) bool { return strings.HasPrefix(method.Name, "~") }
+
+// destructorVariant changes the kind of a mangled destructor name (e.g.,
+// GCC declares _ZN3geo4BaseD4Ev, and the vtable has D1 and D0)
+func destructorVariant(linkageName string, variant string) string {
+	for _, kind := range []string{"D0E", "D1E", "D2E", "D4E", "D5E"} {
+		if index := strings.LastIndex(linkageName, kind); index >= 0 {
+			return linkageName[:index] + variant + linkageName[index+2:]
+		}
+	}
+	return linkageName
 }
 
 // A StructField represents a field in a struct, union, or C++ class type.
@@ -333,6 +665,9 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 
 	nextDepth := 0
 
+	// ADDED: next also returns direct children that have children
+	withNested := false
+
 	// Get next child; set err if error happens.
 	next := func() *Entry {
 		if !e.Children {
@@ -363,6 +698,12 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 			if kid.Children {
 				nextDepth++
 			}
+
+			// ADDED: a struct or class also needs the direct children
+			// with children (e.g., a method), and their children are skipped
+			if withNested && nextDepth == 1 && kid.Children {
+				return kid
+			}
 			if nextDepth > 0 {
 				continue
 			}
@@ -416,14 +757,23 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 			// but haven't seen that in the wild yet.
 			switch kid.Tag {
 			case TagSubrangeType:
+				bound := ArrayBound{Lower: readBound(kid, AttrLowerBound), Upper: readBound(kid, AttrUpperBound),
+					Count: readBound(kid, AttrCount), Stride: readBound(kid, AttrStride)}
+				t.Bounds = append(t.Bounds, bound)
+
 				count, ok := kid.Val(AttrCount).(int64)
 				if !ok {
 					// Old binaries may have an upper bound instead.
 					count, ok = kid.Val(AttrUpperBound).(int64)
 					if ok {
 						count++ // Length is one more than upper bound.
-					} else if len(dims) == 0 {
-						count = -1 // As in x[].
+
+						// ADDED: Fortran arrays can start at any lower bound
+						if bound.Lower.IsConstant {
+							count -= bound.Lower.Constant
+						}
+					} else if len(dims) == 0 || bound.Upper.Dynamic() || bound.Count.Dynamic() {
+						count = -1 // As in x[], or only known at run time
 					}
 				}
 				dims = append(dims, count)
@@ -441,6 +791,24 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 		for i := len(dims) - 1; i >= 1; i-- {
 			t.Type = &ArrayType{Type: t.Type, Count: dims[i]}
 		}
+		t.DataLocation, _ = e.Val(AttrDataLocation).([]byte)
+		t.Rank = readBound(e, AttrRank)
+		t.Original = t
+
+	// ADDED: Fortran character types
+	case TagStringType:
+		// Attributes:
+		//	AttrByteSize: length, for a constant length
+		//	AttrStringLength: where the length is (an expression or variable)
+		t := new(StringType)
+		typ = t
+		typeCache[off] = t
+		t.Name, _ = e.Val(AttrName).(string)
+		if size, ok := e.Val(AttrByteSize).(int64); ok {
+			t.Length = Bound{Constant: size, IsConstant: true}
+		} else {
+			t.Length = readBound(e, AttrStringLength)
+		}
 		t.Original = t
 
 	case TagBaseType:
@@ -497,7 +865,9 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 		t.Name = name
 		t.BitSize, _ = e.Val(AttrBitSize).(int64)
 		t.BitOffset, _ = e.Val(AttrBitOffset).(int64)
-		t.Original = t
+
+		// The original is the specific type (e.g., FloatType), not the embedded BasicType
+		t.Original = typ
 
 	case TagClassType, TagStructType, TagUnionType:
 		// Structure, union, or class type.  (DWARF v2 §5.5)
@@ -530,7 +900,45 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 		t.Field = make([]*StructField, 0, 8)
 		var lastFieldType *Type
 		var lastFieldBitOffset int64
+		withNested = true // ADDED: virtual methods have parameters
 		for kid := next(); kid != nil; kid = next() {
+
+			// ADDED: base classes and virtual methods
+			switch kid.Tag {
+			case TagInheritance:
+				base := &StructBase{Access: defaultAccess(t.Kind)}
+				if base.Type = typeOf(kid); err != nil {
+					goto Error
+				}
+				virtuality, _ := kid.Val(AttrVirtuality).(int64)
+				base.Virtual = virtuality != 0
+				base.ByteOffset, _ = memberOffset(d, kid)
+				if access, ok := kid.Val(AttrAccessibility).(int64); ok {
+					base.Access = accessName(access)
+				}
+				t.Bases = append(t.Bases, base)
+				continue
+
+			case TagSubprogram:
+				if method, ok := virtualMethod(d, kid); ok {
+					t.Methods = append(t.Methods, method)
+				}
+				continue
+
+			case TagTemplateTypeParameter, TagTemplateValueParameter:
+				t.TemplateParams = append(t.TemplateParams, readTemplateParam(kid, typeOf))
+				if err != nil {
+					goto Error
+				}
+				continue
+
+			case TagVariantPart:
+				t.Variants, err = d.readVariantPart(name, r.clone(), kid, typeOf)
+				if err != nil {
+					goto Error
+				}
+				continue
+			}
 			if kid.Tag != TagMember {
 				continue
 			}
@@ -584,10 +992,19 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 		}
 		t.Original = t
 
-		// ADDED: save the struct to the struct cache for later lookup
-		d.StructCache[t.StructName] = t
+		// ADDED: save the struct to the struct cache for later lookup. A name
+		// can be defined in more than one compile unit, so the first definition
+		// is kept by name, and each by its compile unit and offset.
+		if cached, ok := d.StructCache[t.StructName]; !ok || (cached.Incomplete && !t.Incomplete) {
+			d.StructCache[t.StructName] = t
+		}
+		t.Offset = off
+		if u := d.offsetToUnit(off); name == "info" && u >= 0 {
+			t.Unit = d.unit[u].off
+			d.Structs[StructKey{t.Unit, off}] = t
+		}
 
-	case TagConstType, TagVolatileType, TagRestrictType:
+	case TagConstType, TagVolatileType, TagRestrictType, TagAtomicType:
 		// Type modifier (DWARF v2 §5.2)
 		// Attributes:
 		//	AttrType: subtype
@@ -604,6 +1021,8 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 			t.Qual = "restrict"
 		case TagVolatileType:
 			t.Qual = "volatile"
+		case TagAtomicType:
+			t.Qual = "_Atomic"
 		}
 		t.Original = t
 
@@ -646,11 +1065,45 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 		t := new(PtrType)
 		typ = t
 		typeCache[off] = t
+		// ADDED: void * is a pointer too
+		t.Original = t
 		if e.Val(AttrType) == nil {
 			t.Type = &VoidType{}
 			break
 		}
 		t.Type = typeOf(e)
+
+	// ADDED: C++ references
+	case TagReferenceType, TagRvalueReferenceType:
+		// Attributes:
+		//	AttrType: subtype
+		t := new(RefType)
+		typ = t
+		typeCache[off] = t
+		t.Rvalue = e.Tag == TagRvalueReferenceType
+		if t.Type = typeOf(e); err != nil {
+			goto Error
+		}
+		t.Original = t
+
+	// ADDED: C++ pointers to members
+	case TagPtrToMemberType:
+		// Attributes:
+		//	AttrType: type of the member
+		//	AttrContainingType: the class of the member
+		t := new(PtrToMemberType)
+		typ = t
+		typeCache[off] = t
+		if t.Type = typeOf(e); err != nil {
+			goto Error
+		}
+		if class, ok := e.Val(AttrContainingType).(Offset); ok {
+			if t.Class, err = d.ReadType(name, r.clone(), class, typeCache, typedefs); err != nil {
+				goto Error
+			}
+		} else {
+			t.Class = &VoidType{}
+		}
 		t.Original = t
 
 	case TagSubroutineType:
@@ -735,8 +1188,13 @@ func (d *Data) ReadType(name string, r typeReader, off Offset, typeCache map[Off
 				// type's size once the type graph is
 				// constructed.
 				*typedefs = append(*typedefs, t)
-			case *PtrType:
+			case *PtrType, *RefType:
 				b = int64(addressSize)
+			case *PtrToMemberType:
+				b = int64(addressSize)
+				if t.IsFunction() {
+					b *= 2
+				}
 			}
 		}
 		typ.Common().ByteSize = b
@@ -751,6 +1209,158 @@ Error:
 	return nil, err
 }
 
+// ADDED: memberOffset returns a DW_AT_data_member_location that is a constant.
+// A virtual base has an expression to find it at runtime, so it is unknown.
+func memberOffset(d *Data, e *Entry) (int64, bool) {
+	switch loc := e.Val(AttrDataMemberLoc).(type) {
+	case []byte:
+		b := makeBuf(d, unknownFormat{}, "location", 0, loc)
+		if b.uint8() != opPlusUconst {
+			return 0, false
+		}
+		offset := int64(b.uint())
+		return offset, b.err == nil
+	case int64:
+		return loc, true
+	}
+	return 0, false
+}
+
+// ADDED: readVariantPart reads the discriminant and variants of a variant part
+func (d *Data) readVariantPart(name string, r typeReader, part *Entry, typeOf func(*Entry) Type) (*VariantPart, error) {
+	variants := &VariantPart{}
+	discr, hasDiscr := part.Val(AttrDiscr).(Offset)
+
+	r.Seek(part.Offset)
+	if _, err := r.Next(); err != nil {
+		return nil, err
+	}
+
+	// The children are the discriminant (a member) and variants with members
+	var variant *Variant
+	depth := 0
+	if part.Children {
+		depth = 1
+	}
+	for depth > 0 {
+		kid, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if kid == nil {
+			return nil, DecodeError{name, r.offset(), "unexpected end of DWARF entries"}
+		}
+		if kid.Tag == 0 {
+			depth--
+			continue
+		}
+		switch {
+		case depth == 1 && kid.Tag == TagVariant:
+			variant = &Variant{Values: discrValues(d, kid)}
+			variants.Variants = append(variants.Variants, variant)
+
+		case depth == 1 && kid.Tag == TagMember && hasDiscr && kid.Offset == discr:
+			variants.Discriminant = readMember(d, kid, typeOf)
+
+		case depth == 2 && kid.Tag == TagMember && variant != nil:
+			variant.Field = append(variant.Field, readMember(d, kid, typeOf))
+		}
+		if kid.Children {
+			depth++
+		}
+	}
+
+	// The discriminant can also be a member of the enclosing type
+	if hasDiscr && variants.Discriminant == nil {
+		r.Seek(discr)
+		if kid, err := r.Next(); err == nil && kid != nil && kid.Tag == TagMember {
+			variants.Discriminant = readMember(d, kid, typeOf)
+		}
+	}
+	return variants, nil
+}
+
+// ADDED: readMember reads a member that is not a bit field
+func readMember(d *Data, kid *Entry, typeOf func(*Entry) Type) *StructField {
+	f := &StructField{Type: typeOf(kid)}
+	f.Name, _ = kid.Val(AttrName).(string)
+	f.ByteOffset, _ = memberOffset(d, kid)
+	f.ByteSize, _ = kid.Val(AttrByteSize).(int64)
+	return f
+}
+
+// ADDED: discrValues reads DW_AT_discr_value or DW_AT_discr_list of a variant
+func discrValues(d *Data, e *Entry) []DiscrRange {
+	switch value := e.Val(AttrDiscrValue).(type) {
+	case int64:
+		return []DiscrRange{{value, value}}
+	case uint64:
+		return []DiscrRange{{int64(value), int64(value)}}
+	}
+	list, ok := e.Val(AttrDiscrList).([]byte)
+	if !ok {
+		return nil
+	}
+
+	// Each item is DW_DSC_label with a value, or DW_DSC_range with two
+	values := []DiscrRange{}
+	b := makeBuf(d, unknownFormat{}, "discr_list", 0, list)
+	for len(b.data) > 0 && b.err == nil {
+		switch b.uint8() {
+		case 0:
+			value := int64(b.uint())
+			values = append(values, DiscrRange{value, value})
+		case 1:
+			values = append(values, DiscrRange{int64(b.uint()), int64(b.uint())})
+		default:
+			return values
+		}
+	}
+	return values
+}
+
+// ADDED: defaultAccess is the access of a member or base that does not say (DWARF 5 §5.7.6)
+func defaultAccess(kind string) string {
+	if kind == "class" {
+		return "private"
+	}
+	return "public"
+}
+
+// ADDED: accessName converts a DW_ACCESS constant
+func accessName(access int64) string {
+	switch access {
+	case 1:
+		return "public"
+	case 2:
+		return "protected"
+	}
+	return "private"
+}
+
+// ADDED: virtualMethod reads the vtable slot of a virtual member function
+func virtualMethod(d *Data, e *Entry) (*VirtualMethod, bool) {
+	virtuality, _ := e.Val(AttrVirtuality).(int64)
+	if virtuality == 0 {
+		return nil, false
+	}
+
+	// The slot is not always known (e.g., for a virtual destructor)
+	method := &VirtualMethod{Slot: -1}
+	if loc, ok := e.Val(AttrVtableElemLoc).([]byte); ok {
+		b := makeBuf(d, unknownFormat{}, "vtable_elem_location", 0, loc)
+		if b.uint8() == opConstu {
+			method.Slot = int64(b.uint())
+		}
+		if b.err != nil {
+			method.Slot = -1
+		}
+	}
+	method.Name, _ = e.Val(AttrName).(string)
+	method.LinkageName, _ = e.Val(AttrLinkageName).(string)
+	return method, true
+}
+
 func zeroArray(t *Type) {
 	if t == nil {
 		return
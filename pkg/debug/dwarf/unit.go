@@ -21,6 +21,11 @@ type unit struct {
 	vers   int
 	utype  uint8 // DWARF 5 unit type
 	is64   bool  // True for 64-bit DWARF format
+
+	// ADDED: the DWO ID of a skeleton or split unit (in the DWARF 5 header),
+	// and the sections of a split unit read from a .dwo or .dwp file
+	id    uint64
+	split *splitUnit
 }
 
 // Implement the dataFormat interface.
@@ -93,7 +98,7 @@ func (d *Data) parseUnits() ([]unit, error) {
 
 		switch u.utype {
 		case utSkeleton, utSplitCompile:
-			b.uint64() // unit ID
+			u.id = b.uint64() // unit ID
 		case utType, utSplitType:
 			b.uint64()  // type signature
 			if u.is64 { // type offset
@@ -0,0 +1,78 @@
+// Separate debug files, added by @vsoch
+// A stripped binary says where its debug information is: by a build ID
+// (a note that the debug file has too), or by the name and CRC of the debug
+// file in .gnu_debuglink.
+
+package elf
+
+import (
+	"bytes"
+	"errors"
+)
+
+// NT_GNU_BUILD_ID is the type of the note with the build ID
+const NT_GNU_BUILD_ID = 3
+
+// BuildID returns the GNU build ID of the file, or nil if it has none
+func (f *File) BuildID() ([]byte, error) {
+	for _, s := range f.Sections {
+		if s.Type != SHT_NOTE {
+			continue
+		}
+		data, err := s.Data()
+		if err != nil {
+			return nil, err
+		}
+
+		// Each note has the sizes of the name and description, a type, and
+		// the name and description (each padded to 4 bytes)
+		for len(data) >= 12 {
+			namesz := uint64(f.ByteOrder.Uint32(data[0:4]))
+			descsz := uint64(f.ByteOrder.Uint32(data[4:8]))
+			kind := f.ByteOrder.Uint32(data[8:12])
+			name := 12 + (namesz+3)&^3
+			end := name + (descsz+3)&^3
+			if uint64(len(data)) < name+descsz {
+				break
+			}
+			if kind == NT_GNU_BUILD_ID && bytes.Equal(data[12:12+namesz], []byte("GNU\x00")) {
+				return data[name : name+descsz], nil
+			}
+			if uint64(len(data)) < end {
+				break
+			}
+			data = data[end:]
+		}
+	}
+	return nil, nil
+}
+
+// DebugLink returns the name and CRC (of the whole file) of the separate debug
+// file named in .gnu_debuglink
+func (f *File) DebugLink() (string, uint32, error) {
+	s := f.Section(".gnu_debuglink")
+	if s == nil {
+		return "", 0, errors.New("no .gnu_debuglink section")
+	}
+	data, err := s.Data()
+	if err != nil {
+		return "", 0, err
+	}
+
+	// The name ends with a NUL, and the CRC follows (aligned to 4 bytes)
+	end := bytes.IndexByte(data, 0)
+	if end <= 0 {
+		return "", 0, errors.New("bad .gnu_debuglink section")
+	}
+	offset := (end + 4) &^ 3
+	if len(data) < offset+4 {
+		return "", 0, errors.New("bad .gnu_debuglink section")
+	}
+	return string(data[:end]), f.ByteOrder.Uint32(data[offset : offset+4]), nil
+}
+
+// HasDWARF determines if the file has debug information (and not only a
+// skeleton, if it is split into .dwo files)
+func (f *File) HasDWARF() bool {
+	return f.Section(".debug_info") != nil || f.Section(".zdebug_info") != nil
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+	"github.com/klauspost/compress/zstd"
 	"github.com/vsoch/gosmeagle/pkg/debug/dwarf"
 	"io"
 	"os"
@@ -102,9 +103,37 @@ type Section struct {
 // Even if the section is stored compressed in the ELF file,
 // Data returns uncompressed data.
 func (s *Section) Data() ([]byte, error) {
-	dat := make([]byte, s.Size)
-	n, err := io.ReadFull(s.Open(), dat)
-	return dat[0:n], err
+	if !s.compressed() {
+		dat := make([]byte, s.Size)
+		n, err := io.ReadFull(s.Open(), dat)
+		return dat[0:n], err
+	}
+
+	// ADDED: the uncompressed size comes from the (untrusted) header, so it is
+	// limited, and the buffer grows with the data that is actually there
+	if s.Size > MaxSectionSize {
+		return nil, &FormatError{int64(s.Offset), "compressed section is too large", s.Size}
+	}
+	size := s.Size
+	if limit := 32 * s.FileSize; size > limit {
+		size = limit
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, size))
+	n, err := buf.ReadFrom(io.LimitReader(s.Open(), int64(s.Size)))
+	if err == nil && uint64(n) < s.Size {
+		err = io.ErrUnexpectedEOF
+	}
+	return buf.Bytes(), err
+}
+
+// ADDED: MaxSectionSize is the largest size of a compressed section that Data
+// decompresses
+var MaxSectionSize uint64 = 4 << 30
+
+// ADDED: compressed determines if the section is compressed, either with the
+// SHF_COMPRESSED flag, or as a GNU .zdebug section
+func (s *Section) compressed() bool {
+	return s.Flags&SHF_COMPRESSED != 0 || s.compressionType != 0
 }
 
 // stringTable reads and returns the string table given by the
@@ -120,10 +149,11 @@ func (f *File) stringTable(link uint32) ([]byte, error) {
 // Even if the section is stored compressed in the ELF file,
 // the ReadSeeker reads uncompressed data.
 func (s *Section) Open() io.ReadSeeker {
-	if s.Flags&SHF_COMPRESSED == 0 {
+	if !s.compressed() {
 		return io.NewSectionReader(s.sr, 0, 1<<63-1)
 	}
-	if s.compressionType == COMPRESS_ZLIB {
+	switch s.compressionType {
+	case COMPRESS_ZLIB:
 		return &readSeekerFromReader{
 			reset: func() (io.Reader, error) {
 				fr := io.NewSectionReader(s.sr, s.compressionOffset, int64(s.FileSize)-s.compressionOffset)
@@ -131,6 +161,25 @@ func (s *Section) Open() io.ReadSeeker {
 			},
 			size: int64(s.Size),
 		}
+
+	// ADDED: zstd is decoded in this goroutine, with a window no larger than
+	// the section (it is the most memory it can need)
+	case COMPRESS_ZSTD:
+		return &readSeekerFromReader{
+			reset: func() (io.Reader, error) {
+				fr := io.NewSectionReader(s.sr, s.compressionOffset, int64(s.FileSize)-s.compressionOffset)
+				window := uint64(zstd.MinWindowSize)
+				if s.Size > window {
+					window = s.Size
+				}
+				return zstd.NewReader(fr,
+					zstd.WithDecoderConcurrency(1),
+					zstd.WithDecoderLowmem(true),
+					zstd.WithDecoderMaxMemory(MaxSectionSize),
+					zstd.WithDecoderMaxWindow(window))
+			},
+			size: int64(s.Size),
+		}
 	}
 	err := &FormatError{int64(s.Offset), "unknown compression type", s.compressionType}
 	return errorReader{err}
@@ -463,6 +512,18 @@ func NewFile(r io.ReaderAt) (*File, error) {
 		if !ok {
 			return nil, &FormatError{shoff + int64(i*shentsize), "bad section name index", names[i]}
 		}
+
+		// ADDED: older GNU toolchains compress .zdebug sections, with "ZLIB"
+		// and the uncompressed size (big endian) before the zlib stream
+		if strings.HasPrefix(s.Name, ".zdebug_") && s.Flags&SHF_COMPRESSED == 0 && s.Type != SHT_NOBITS {
+			var header [12]byte
+			if _, err := s.sr.ReadAt(header[:], 0); err == nil && string(header[:4]) == "ZLIB" {
+				s.compressionType = COMPRESS_ZLIB
+				s.compressionOffset = int64(len(header))
+				s.Size = binary.BigEndian.Uint64(header[4:])
+				s.ReaderAt = nil
+			}
+		}
 	}
 
 	return f, nil
@@ -1152,22 +1213,6 @@ func (f *File) DWARF() (*dwarf.Data, error) {
 			return nil, err
 		}
 
-		if len(b) >= 12 && string(b[:4]) == "ZLIB" {
-			dlen := binary.BigEndian.Uint64(b[4:12])
-			dbuf := make([]byte, dlen)
-			r, err := zlib.NewReader(bytes.NewBuffer(b[12:]))
-			if err != nil {
-				return nil, err
-			}
-			if _, err := io.ReadFull(r, dbuf); err != nil {
-				return nil, err
-			}
-			if err := r.Close(); err != nil {
-				return nil, err
-			}
-			b = dbuf
-		}
-
 		for _, r := range f.Sections {
 			if r.Type != SHT_RELA && r.Type != SHT_REL {
 				continue
@@ -1243,6 +1288,36 @@ func (f *File) DWARF() (*dwarf.Data, error) {
 	return d, nil
 }
 
+// ADDED: DWOSections returns the sections of a split DWARF file (a .dwo file,
+// or a .dwp package file that combines them), by name without the prefix and
+// suffix (e.g., "info" for .debug_info.dwo, and "cu_index" for .debug_cu_index)
+func (f *File) DWOSections() (map[string][]byte, error) {
+	sections := map[string][]byte{}
+	for _, s := range f.Sections {
+		var name string
+		switch {
+		case strings.HasPrefix(s.Name, ".debug_"):
+			name = s.Name[7:]
+		case strings.HasPrefix(s.Name, ".zdebug_"):
+			name = s.Name[8:]
+		default:
+			continue
+		}
+		switch {
+		case strings.HasSuffix(name, ".dwo"):
+			name = strings.TrimSuffix(name, ".dwo")
+		case name != "cu_index" && name != "tu_index":
+			continue
+		}
+		b, err := s.Data()
+		if err != nil {
+			return nil, err
+		}
+		sections[name] = b
+	}
+	return sections, nil
+}
+
 // Symbols returns the symbol table for f. The symbols will be listed in the order
 // they appear in f.
 //